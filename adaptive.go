@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ===============================
+// 自适应采样
+// ===============================
+
+// 固定轮次对稳定节点浪费时间，对抖动大的节点又样本不足。自适应采样在每轮结束后
+// 检查每个组合（endpoint × target）TTFB P50 / P95 的 95% 置信区间，两者都窄于目标宽度时
+// 该组合停止采样，其余组合继续，直到全部收敛、达到 max_count 或 duration。
+
+// defaultAdaptiveMinCount 未配置 min_count 时的最少轮次
+const defaultAdaptiveMinCount = 10
+
+// P95 的 bootstrap 区间取决于 P95 以上的尾部样本：尾部只有一两个样本时重采样几乎取不到更大的值，
+// 区间退化得很窄，会被误判为收敛。至少要有 minTailSamples 个尾部样本（n × 5% ≥ 5）才判断收敛
+const (
+	minTailSamples     = 5
+	minConvergeSamples = int(minTailSamples / (1 - 0.95)) // 100 个成功样本
+)
+
+// AdaptiveConfig 自适应采样配置，Width 为 0 表示不启用
+type AdaptiveConfig struct {
+	Width    float64 // 目标置信区间宽度：Relative 时为相对点估计的比例，否则为毫秒
+	Relative bool    // 宽度是否按点估计的百分比计算
+	MinCount int     // 至少采样的轮次（且至少有同样多、不少于 minConvergeSamples 的成功样本）才判断收敛
+	MaxCount int     // 最多采样的轮次（0 表示不限，由 duration 结束）
+}
+
+// Enabled 是否启用自适应采样
+func (a AdaptiveConfig) Enabled() bool {
+	return a.Width > 0
+}
+
+// String 目标宽度，如 "5%" 或 "20ms"
+func (a AdaptiveConfig) String() string {
+	if a.Relative {
+		return formatFloat(a.Width*100) + "%"
+	}
+	return formatFloat(a.Width) + "ms"
+}
+
+// parseCIWidth 解析目标置信区间宽度："5%" 为相对宽度，"20ms" / "0.02s" / "20" 为绝对宽度（毫秒）
+func parseCIWidth(s string) (width float64, relative bool, err error) {
+	if strings.HasSuffix(strings.TrimSpace(s), "%") {
+		v, err := parsePercent(s)
+		if err != nil {
+			return 0, false, err
+		}
+		width, relative = v/100, true
+	} else {
+		width, err = parseSLOValue(s, "ms")
+		if err != nil {
+			return 0, false, err
+		}
+	}
+	if width <= 0 {
+		return 0, false, fmt.Errorf("置信区间宽度必须大于 0，当前为 %q", s)
+	}
+	return width, relative, nil
+}
+
+// narrow 置信区间是否不宽于目标宽度
+func (a AdaptiveConfig) narrow(ci ConfidenceInterval, estimate float64) bool {
+	if a.Relative {
+		return estimate > 0 && ci.Width()/estimate <= a.Width
+	}
+	return ci.Width() <= a.Width
+}
+
+// relativeWidth 置信区间宽度相对点估计的百分比
+func relativeWidth(ci ConfidenceInterval, estimate float64) string {
+	if estimate <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", ci.Width()/estimate*100)
+}
+
+// enoughSamples 成功样本是否足够判断收敛
+func (a AdaptiveConfig) enoughSamples(n int) bool {
+	return n >= max(a.MinCount, minConvergeSamples)
+}
+
+// withinTarget P50 / P95 置信区间是否都不宽于目标宽度
+func (a AdaptiveConfig) withinTarget(p50, p95 float64, p50CI, p95CI ConfidenceInterval) bool {
+	return a.narrow(p50CI, p50) && a.narrow(p95CI, p95)
+}
+
+// converged 组合是否已收敛：成功样本足够，且 P50 / P95 置信区间都不宽于目标宽度
+func (a AdaptiveConfig) converged(s Summary) bool {
+	return a.enoughSamples(s.SuccessCount) && a.withinTarget(s.TTFBP50, s.TTFBP95, s.TTFBP50CI, s.TTFBP95CI)
+}
+
+// pending 返回尚未收敛的组合，已收敛的组合不再参与后续轮次
+// 每轮只对样本足够的组合做 P50 / P95 的 bootstrap（不计算均值和完整汇总，重采样无需排序）
+func (a AdaptiveConfig) pending(clients []EndpointClient, endpointResults map[string][]RequestResult, round int) []EndpointClient {
+	if round < a.MinCount {
+		return clients
+	}
+	var pending []EndpointClient
+	for _, ec := range clients {
+		key := reportKey(ec.Endpoint, ec.Target)
+		values := successTTFBs(endpointResults[key])
+		if !a.enoughSamples(len(values)) {
+			pending = append(pending, ec)
+			continue
+		}
+		p50CI, p95CI := bootstrapPercentileCIs(values)
+		if a.withinTarget(percentile(values, 0.50), percentile(values, 0.95), p50CI, p95CI) {
+			logger.Printf("  ✅ %s 置信区间已收敛（第 %d 轮，P50 CI 宽度 %.2fms，P95 CI 宽度 %.2fms），停止采样\n",
+				key, round, p50CI.Width(), p95CI.Width())
+			continue
+		}
+		pending = append(pending, ec)
+	}
+	return pending
+}
+
+// applyAdaptiveStats 在汇总中补充实际采样轮次和是否收敛
+func applyAdaptiveStats(s *Summary, results []RequestResult, a AdaptiveConfig) {
+	for _, r := range results {
+		s.Rounds = max(s.Rounds, r.Index)
+	}
+	s.Converged = a.converged(*s)
+}
+
+// printAdaptiveTable 打印自适应采样的轮次和置信区间宽度
+func printAdaptiveTable(summaries []Summary, a AdaptiveConfig) {
+	fmt.Printf("\n🎯 自适应采样 (目标 95%% CI 宽度 %s):\n", a)
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{
+			"节点", "协议", "轮次", "样本", "P50", "P50-CI宽度", "P50-相对宽度",
+			"P95", "P95-CI宽度", "P95-相对宽度", "状态",
+		}),
+	)
+
+	for _, s := range summaries {
+		status := "⏱️ 未收敛"
+		if s.Converged {
+			status = "✅ 已收敛"
+		}
+		table.Append([]string{
+			s.Label(),
+			s.Protocol,
+			fmt.Sprintf("%d", s.Rounds),
+			fmt.Sprintf("%d", s.SuccessCount),
+			fmt.Sprintf("%.2f", s.TTFBP50),
+			fmt.Sprintf("%.2f", s.TTFBP50CI.Width()),
+			relativeWidth(s.TTFBP50CI, s.TTFBP50),
+			fmt.Sprintf("%.2f", s.TTFBP95),
+			fmt.Sprintf("%.2f", s.TTFBP95CI.Width()),
+			relativeWidth(s.TTFBP95CI, s.TTFBP95),
+			status,
+		})
+	}
+
+	table.Render()
+	fmt.Printf("\n💡 说明: 每轮结束后检查 TTFB P50 / P95 的 95%% 置信区间，两者都不宽于目标宽度的节点停止采样；成功样本少于 %d 个时 P95 尾部样本不足，不判断收敛\n", minConvergeSamples)
+	fmt.Println("   - 未收敛表示达到 max_count、duration 或被中断时区间仍偏宽（或样本不足），可放宽目标或增加上限")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ===============================
+// 并发测试（多流 / 多连接）
+// ===============================
+
+// 每轮每个节点同时发起 concurrency 个请求：single_conn 时共用一个连接，
+// 对比 HTTP/2、HTTP/3 的多路复用与 HTTP/1.1 的排队；per_request_conn 时每个请求使用独立连接。
+
+// applyBurstStats 按轮次分组统计轮内延迟差异和实际新建的连接数
+func applyBurstStats(s *Summary, results []RequestResult) {
+	type burst struct {
+		conns int
+		ttfb  []float64
+	}
+	var order []int
+	bursts := make(map[int]*burst)
+	for _, r := range results {
+		if r.Stream == 0 {
+			continue
+		}
+		b, ok := bursts[r.Index]
+		if !ok {
+			b = &burst{conns: r.BurstConns}
+			bursts[r.Index] = b
+			order = append(order, r.Index)
+		}
+		if r.Stream > s.Concurrency {
+			s.Concurrency = r.Stream
+		}
+		if r.Error == "" {
+			b.ttfb = append(b.ttfb, durationMs(r.TTFB))
+		}
+	}
+	if len(order) == 0 {
+		return
+	}
+
+	// 极差只统计至少两个流成功的轮次
+	var fastest, slowest, spreads []float64
+	for _, idx := range order {
+		b := bursts[idx]
+		s.ConnsOpened += b.conns
+		if len(b.ttfb) < 2 {
+			continue
+		}
+		lo, hi := percentile(b.ttfb, 0), percentile(b.ttfb, 1)
+		fastest = append(fastest, lo)
+		slowest = append(slowest, hi)
+		spreads = append(spreads, hi-lo)
+	}
+	s.BurstCount = len(order)
+	s.ConnsPerBurst = float64(s.ConnsOpened) / float64(len(order))
+	s.BurstFastestAvg = average(fastest)
+	s.BurstSlowestAvg = average(slowest)
+	s.BurstSpreadAvg = average(spreads)
+	s.BurstSpreadP50 = percentile(spreads, 0.50)
+	s.BurstSpreadP95 = percentile(spreads, 0.95)
+}
+
+// printBurstTable 打印并发测试的轮内延迟差异和连接数
+func printBurstTable(summaries []Summary, multiplex Multiplex) {
+	fmt.Printf("\n🔀 并发测试 (%s):\n", multiplex)
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{
+			"节点", "协议", "并发", "轮次", "新建连接", "每轮连接",
+			"单流-P50", "单流-P95", "最快流均值", "最慢流均值",
+			"极差均值", "极差-P50", "极差-P95",
+		}),
+	)
+
+	for _, s := range summaries {
+		table.Append([]string{
+			s.Label(),
+			s.Protocol,
+			fmt.Sprintf("%d", s.Concurrency),
+			fmt.Sprintf("%d", s.BurstCount),
+			fmt.Sprintf("%d", s.ConnsOpened),
+			fmt.Sprintf("%.1f", s.ConnsPerBurst),
+			fmt.Sprintf("%.2f", s.TTFBP50),
+			fmt.Sprintf("%.2f", s.TTFBP95),
+			fmt.Sprintf("%.2f", s.BurstFastestAvg),
+			fmt.Sprintf("%.2f", s.BurstSlowestAvg),
+			fmt.Sprintf("%.2f", s.BurstSpreadAvg),
+			fmt.Sprintf("%.2f", s.BurstSpreadP50),
+			fmt.Sprintf("%.2f", s.BurstSpreadP95),
+		})
+	}
+
+	table.Render()
+	fmt.Println("\n💡 说明: 单流 = 每个并发请求各自的 TTFB(ms)；极差 = 同一轮内最慢流与最快流的 TTFB 差值；新建连接按实际建连次数统计（single_conn 热连接下应只在首轮建连，HTTP/1.1 的并发请求会在同一连接上排队）")
+}
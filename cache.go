@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ===============================
+// 缓存状态识别
+// ===============================
+
+// 归一化的缓存状态
+const (
+	CacheHit     = "HIT"
+	CacheMiss    = "MISS"
+	CacheStale   = "STALE"
+	CacheBypass  = "BYPASS"
+	CacheUnknown = "" // 无法识别（没有缓存相关响应头）
+)
+
+// 关键字匹配顺序：同时包含多个关键字时（如 "STALE HIT"）取靠前的状态
+var cacheStatusOrder = []string{CacheStale, CacheBypass, CacheMiss, CacheHit}
+
+// CacheRules 缓存状态识别规则
+type CacheRules struct {
+	Headers  []string            // 按顺序检查的响应头，取第一个能识别的
+	Keywords map[string][]string // 归一化状态 -> 关键字（不区分大小写，按单词匹配）
+}
+
+// 默认规则：覆盖常见 CDN 与 RFC 9211 Cache-Status
+var defaultCacheRules = CacheRules{
+	Headers: []string{"Cache-Status", "CF-Cache-Status", "X-Cache-Status", "X-Cache", "Age"},
+	Keywords: map[string][]string{
+		CacheHit:    {"hit", "refreshhit", "revalidated"},
+		CacheMiss:   {"miss", "expired"},
+		CacheStale:  {"stale", "updating"},
+		CacheBypass: {"bypass", "dynamic", "pass"},
+	},
+}
+
+// classifyCacheStatus 按规则识别缓存状态，返回状态和命中的响应头
+func classifyCacheStatus(header http.Header, rules CacheRules) (string, string) {
+	for _, name := range rules.Headers {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+
+		var status string
+		switch {
+		case strings.EqualFold(name, "Cache-Status"):
+			status = parseCacheStatusHeader(value)
+		case strings.EqualFold(name, "Age"):
+			// Age > 0 说明响应来自缓存
+			if age, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && age > 0 {
+				status = CacheHit
+			}
+		default:
+			status = matchCacheKeywords(lastListMember(value), rules.Keywords)
+		}
+		if status != CacheUnknown {
+			return status, name
+		}
+	}
+	return CacheUnknown, ""
+}
+
+// parseCacheStatusHeader 解析 RFC 9211 Cache-Status 头
+// 多级缓存时最后一项离用户最近，例如: "Origin; hit, Edge; fwd=uri-miss; stored"
+func parseCacheStatusHeader(value string) string {
+	params := strings.Split(lastListMember(value), ";")
+	var hit bool
+	var fwd string
+	var ttl *int
+	for _, p := range params[1:] {
+		key, val, _ := strings.Cut(strings.TrimSpace(p), "=")
+		switch strings.ToLower(key) {
+		case "hit":
+			hit = true
+		case "fwd":
+			fwd = strings.ToLower(strings.Trim(val, `"`))
+		case "ttl":
+			if v, err := strconv.Atoi(val); err == nil {
+				ttl = &v
+			}
+		}
+	}
+
+	switch {
+	case hit && ttl != nil && *ttl < 0:
+		return CacheStale
+	case hit:
+		return CacheHit
+	case fwd == "stale":
+		return CacheStale
+	case fwd == "bypass" || fwd == "request":
+		return CacheBypass
+	case fwd != "":
+		return CacheMiss
+	default:
+		return CacheUnknown
+	}
+}
+
+// matchCacheKeywords 按单词匹配关键字
+func matchCacheKeywords(value string, keywords map[string][]string) string {
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, status := range cacheStatusOrder {
+		for _, kw := range keywords[status] {
+			for _, w := range words {
+				if w == strings.ToLower(kw) {
+					return status
+				}
+			}
+		}
+	}
+	return CacheUnknown
+}
+
+// lastListMember 取逗号分隔列表的最后一项（多级缓存时离用户最近的一层）
+func lastListMember(value string) string {
+	if i := strings.LastIndex(value, ","); i >= 0 {
+		return strings.TrimSpace(value[i+1:])
+	}
+	return strings.TrimSpace(value)
+}
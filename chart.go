@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ===============================
+// 内联 SVG 图表
+// ===============================
+
+// 报告直接内嵌 Go 生成的 SVG，不依赖外部图表库，离线打开也能正常显示
+
+// 图表画布尺寸（viewBox 坐标，实际按容器宽度等比缩放）
+const (
+	chartWidth        = 1000
+	chartMarginLeft   = 64
+	chartMarginRight  = 20
+	chartMarginTop    = 16
+	chartMarginBottom = 44
+	chartMaxXLabels   = 12
+	chartYTicks       = 5
+)
+
+// chartSeries 折线图中的一条线，缺失值为 NaN
+type chartSeries struct {
+	Label  string
+	Color  string
+	Values []float64
+}
+
+// lineChart 折线图
+type lineChart struct {
+	Height   int
+	XLabels  []string
+	XTitle   string
+	YTitle   string
+	Unit     string // 数据点提示中的单位
+	SpanGaps bool   // 缺失值处是否连线（否则断开）
+	Series   []chartSeries
+}
+
+// HTML 渲染为内联 SVG 和图例
+func (c lineChart) HTML() template.HTML {
+	plotW := float64(chartWidth - chartMarginLeft - chartMarginRight)
+	plotH := float64(c.Height - chartMarginTop - chartMarginBottom)
+	n := len(c.XLabels)
+
+	// Y 轴范围包含 0 和全部数据点：CDN 延迟（TTFB - 服务端耗时）可能为负
+	minY, maxY := 0.0, 0.0
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			if math.IsNaN(v) {
+				continue
+			}
+			minY, maxY = math.Min(minY, v), math.Max(maxY, v)
+		}
+	}
+	step := niceStep((maxY - minY) / chartYTicks)
+	bottom := math.Floor(minY/step) * step
+	top := math.Max(bottom+step*chartYTicks, math.Ceil(maxY/step)*step)
+	ticks := int(math.Round((top - bottom) / step))
+
+	x := func(i int) float64 {
+		if n <= 1 {
+			return chartMarginLeft + plotW/2
+		}
+		return chartMarginLeft + plotW*float64(i)/float64(n-1)
+	}
+	y := func(v float64) float64 {
+		return chartMarginTop + plotH*(1-(v-bottom)/(top-bottom))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<div class="svg-chart"><svg viewBox="0 0 %d %d" width="100%%" role="img" font-size="12" font-family="-apple-system, 'Segoe UI', Roboto, sans-serif">`, chartWidth, c.Height)
+
+	// Y 轴网格和刻度，有负值时 0 刻度线加亮
+	for i := 0; i <= ticks; i++ {
+		v := roundTo(bottom+step*float64(i), step)
+		if v == 0 {
+			v = 0 // 避免显示 -0
+		}
+		stroke := "rgba(255,255,255,0.06)"
+		if v == 0 && bottom < 0 {
+			stroke = "rgba(255,255,255,0.25)"
+		}
+		fmt.Fprintf(&b, `<line x1="%d" y1="%s" x2="%d" y2="%s" stroke="%s"/>`,
+			chartMarginLeft, svgNum(y(v)), chartWidth-chartMarginRight, svgNum(y(v)), stroke)
+		fmt.Fprintf(&b, `<text x="%d" y="%s" fill="#888" text-anchor="end" dominant-baseline="middle">%s</text>`,
+			chartMarginLeft-8, svgNum(y(v)), formatFloat(v))
+	}
+
+	// X 轴刻度，数据点较多时只标注部分
+	every := 1
+	if n > chartMaxXLabels {
+		every = (n + chartMaxXLabels - 1) / chartMaxXLabels
+	}
+	for i, label := range c.XLabels {
+		if i%every != 0 && i != n-1 {
+			continue
+		}
+		fmt.Fprintf(&b, `<text x="%s" y="%d" fill="#888" text-anchor="middle">%s</text>`,
+			svgNum(x(i)), c.Height-chartMarginBottom+18, template.HTMLEscapeString(label))
+	}
+
+	// 轴标题
+	if c.XTitle != "" {
+		fmt.Fprintf(&b, `<text x="%s" y="%d" fill="#888" text-anchor="middle">%s</text>`,
+			svgNum(chartMarginLeft+plotW/2), c.Height-6, template.HTMLEscapeString(c.XTitle))
+	}
+	if c.YTitle != "" {
+		fmt.Fprintf(&b, `<text transform="translate(14 %s) rotate(-90)" fill="#888" text-anchor="middle">%s</text>`,
+			svgNum(chartMarginTop+plotH/2), template.HTMLEscapeString(c.YTitle))
+	}
+
+	// 折线和数据点（数据点带悬停提示）
+	for _, s := range c.Series {
+		var path strings.Builder
+		pen := false
+		for i, v := range s.Values {
+			if i >= n {
+				break
+			}
+			if math.IsNaN(v) {
+				if !c.SpanGaps {
+					pen = false
+				}
+				continue
+			}
+			cmd := "L"
+			if !pen {
+				cmd = "M"
+				pen = true
+			}
+			fmt.Fprintf(&path, "%s%s %s ", cmd, svgNum(x(i)), svgNum(y(v)))
+		}
+		if path.Len() > 0 {
+			fmt.Fprintf(&b, `<path d="%s" fill="none" stroke="%s" stroke-width="2" stroke-linejoin="round"/>`,
+				strings.TrimSpace(path.String()), s.Color)
+		}
+		for i, v := range s.Values {
+			if i >= n || math.IsNaN(v) {
+				continue
+			}
+			fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="3" fill="%s"><title>%s %s: %s %s</title></circle>`,
+				svgNum(x(i)), svgNum(y(v)), s.Color,
+				template.HTMLEscapeString(s.Label), template.HTMLEscapeString(c.XLabels[i]),
+				strconv.FormatFloat(v, 'f', 2, 64), c.Unit)
+		}
+	}
+	b.WriteString(`</svg>`)
+
+	// 图例
+	b.WriteString(`<div class="chart-legend">`)
+	for _, s := range c.Series {
+		fmt.Fprintf(&b, `<span class="legend-item"><span class="legend-color" style="background: %s"></span>%s</span>`,
+			s.Color, template.HTMLEscapeString(s.Label))
+	}
+	b.WriteString(`</div></div>`)
+
+	return template.HTML(b.String())
+}
+
+// niceStep 把刻度间隔取整为 1 / 2 / 5 × 10^n
+func niceStep(raw float64) float64 {
+	if raw <= 0 || math.IsNaN(raw) {
+		return 1
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch f := raw / mag; {
+	case f <= 1:
+		return mag
+	case f <= 2:
+		return 2 * mag
+	case f <= 5:
+		return 5 * mag
+	default:
+		return 10 * mag
+	}
+}
+
+// roundTo 消除刻度值的浮点误差（如 0.30000000000000004）
+func roundTo(v, step float64) float64 {
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
+
+// svgNum 坐标保留 1 位小数，减小文件体积
+func svgNum(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
+
+// requestChart 单个节点每次请求的 TTFB / CDN 延迟 / 服务端响应折线图
+func requestChart(results []RequestResult) template.HTML {
+	labels := make([]string, len(results))
+	ttfb := make([]float64, len(results))
+	cdn := make([]float64, len(results))
+	server := make([]float64, len(results))
+	for i, r := range results {
+		labels[i] = r.Seq()
+		ttfb[i], cdn[i], server[i] = math.NaN(), math.NaN(), math.NaN()
+		if r.Error != "" {
+			continue
+		}
+		ttfb[i] = float64(r.TTFB.Microseconds()) / 1000.0
+		if r.XResponseTime > 0 {
+			cdn[i] = r.CDNLatency
+			server[i] = r.XResponseTime
+		}
+	}
+	return lineChart{
+		Height:  300,
+		XLabels: labels,
+		XTitle:  "请求序号",
+		YTitle:  "延迟 (ms)",
+		Unit:    "ms",
+		Series: []chartSeries{
+			{Label: "TTFB (ms)", Color: "#00d4ff", Values: ttfb},
+			{Label: "CDN 延迟 (ms)", Color: "#10b981", Values: cdn},
+			{Label: "服务端响应 (ms)", Color: "#8b5cf6", Values: server},
+		},
+	}.HTML()
+}
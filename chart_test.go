@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var (
+	circleYPattern = regexp.MustCompile(`<circle cx="[^"]+" cy="([^"]+)"`)
+	yTickPattern   = regexp.MustCompile(`text-anchor="end" dominant-baseline="middle">([^<]+)</text>`)
+)
+
+// renderChart 渲染折线图，返回数据点纵坐标和 Y 轴刻度标签
+func renderChart(t *testing.T, values ...float64) (ys []float64, ticks []string) {
+	t.Helper()
+	labels := make([]string, len(values))
+	for i := range labels {
+		labels[i] = strconv.Itoa(i + 1)
+	}
+	html := string(lineChart{
+		Height:  300,
+		XLabels: labels,
+		Series:  []chartSeries{{Label: "CDN 延迟 (ms)", Color: "#10b981", Values: values}},
+	}.HTML())
+	for _, m := range circleYPattern.FindAllStringSubmatch(html, -1) {
+		y, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			t.Fatalf("无效的纵坐标 %q", m[1])
+		}
+		ys = append(ys, y)
+	}
+	for _, m := range yTickPattern.FindAllStringSubmatch(html, -1) {
+		ticks = append(ticks, m[1])
+	}
+	return ys, ticks
+}
+
+func TestLineChartRange(t *testing.T) {
+	const plotTop, plotBottom = chartMarginTop, 300 - chartMarginBottom
+
+	tests := []struct {
+		name   string
+		values []float64
+		ticks  string // Y 轴刻度（自下而上）
+	}{
+		{"非负数据", []float64{12, 41, 30}, "0 10 20 30 40 50"},
+		{"含负值", []float64{-12, 5, 30}, "-20 -10 0 10 20 30"},
+		{"全部为负", []float64{-3, -8}, "-8 -6 -4 -2 0 2"},
+		{"全部为 0", []float64{0, 0}, "0 1 2 3 4 5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ys, ticks := renderChart(t, append(tt.values, math.NaN())...)
+			if got := strings.Join(ticks, " "); got != tt.ticks {
+				t.Errorf("Y 轴刻度 = %s, 期望 %s", got, tt.ticks)
+			}
+			if len(ys) != len(tt.values) {
+				t.Fatalf("数据点数 = %d, 期望 %d（缺失值不绘制）", len(ys), len(tt.values))
+			}
+			for i, y := range ys {
+				if y < plotTop || y > plotBottom {
+					t.Errorf("数据点 %g 的纵坐标 %g 超出绘图区 [%d, %d]", tt.values[i], y, plotTop, plotBottom)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ===============================
+// 命令行
+// ===============================
+
+// 进程退出码
+const (
+	exitOK          = 0   // 成功
+	exitError       = 1   // 配置、参数或运行错误
+	exitCheckFailed = 2   // 回归检查或 SLO 检查未通过
+	exitInterrupted = 130 // 被 Ctrl-C 中断（仍会输出部分报告）
+)
+
+const usage = `CDN 延迟测试工具
+
+用法:
+  cdn-test [config.yaml]                      使用配置文件运行测试（等同于 run）
+  cdn-test run [config.yaml] [参数]           运行测试
+  cdn-test validate [config.yaml] [参数]      检查配置，不发起任何网络请求
+  cdn-test report <report.json> [--output 目录] [--csv] [--ndjson]
+                                              从 JSON 报告重新生成 HTML 报告（可同时导出原始样本）
+  cdn-test compare <baseline.json> <current.json> [-c config.yaml] [--threshold 规则]
+                                              对比两次测试报告，按阈值做回归检查
+  cdn-test monitor [config.yaml] [参数]        守护模式：持续测试并提供 Prometheus /metrics
+  cdn-test history [config.yaml] [--days 30] [--endpoint 名称] [--html]
+                                              查询历史记录中每个节点的每日趋势
+  cdn-test fakecdn [--dir 目录] [参数]         启动本地模拟 CDN（H1/H2/H3/明文/h2c），用于离线端到端测试
+
+run / validate 参数（覆盖配置文件中的值）:
+  -c, --config 文件          配置文件路径（默认 config.yaml）
+  --count N                  每个节点测试次数
+  --interval 时长            请求间隔，如 200ms
+  --duration 时长            按时长测试：持续发起轮次直到达到该时长，如 10m（不再按 --count 结束）
+  --ci-width 宽度            自适应采样：各节点 TTFB P50/P95 的 95% 置信区间窄于该宽度后停止，如 5% 或 20ms
+  --rps N                    开环压测：每个节点按固定速率发送（每秒请求数），代替按轮次测试
+  --load-duration 时长       开环压测持续时间，如 1m（默认 30s）
+  --concurrency N            每轮每个节点同时发起 N 个请求
+  --multiplex 方式           并发请求的连接复用方式: single_conn（共用一个连接）/ per_request_conn（每个请求独立连接）
+  --domain 域名              测试域名
+  --endpoint name=ip:proto   测试节点，可重复，指定后替换配置文件中的节点
+                             如 --endpoint 节点A=1.2.3.4:h2 --endpoint 节点B=[2001:db8::1]:h3
+  --output 目录              输出目录
+  --baseline 文件            基线 JSON 报告，测试结束后做回归检查
+  --threshold 规则           回归阈值，可重复，如 ttfb_p95:+15%、success_rate>=99.5、ttfb_p99<=300
+
+history 参数:
+  --days N                   查询最近 N 天（默认 30）
+  --endpoint 名称            只显示名称包含该字符串的节点
+  --html                     同时生成 HTML 趋势页
+  --db 文件                  历史记录文件（默认取配置中的 history.path）
+  --output 目录              趋势页输出目录（默认取配置中的输出目录）
+
+monitor 参数（另可使用上面的配置参数）:
+  --listen 地址              /metrics 监听地址，如 :9108、127.0.0.1:9108
+  --every 时长               每轮测试的调度周期，如 30s
+
+fakecdn 参数:
+  --dir 目录                 写入 ca.pem 和 config.yaml 的目录（默认 ./fakecdn）
+  --host 地址                监听地址（默认 127.0.0.1）
+  --port N                   HTTPS / HTTP/3 端口（默认随机）
+  --domain 域名              证书域名（默认 fake-cdn.test）
+  --delay 时长               首字节前的固定延迟，如 20ms
+  --jitter 时长              随机附加延迟上限
+  --server-time 时长         模拟源站耗时，写入 x-source-response-time
+  --error-rate 比例          返回错误状态码的比例（0-1）
+  --error-status 状态码      错误状态码（默认 503）
+  --reset-rate 比例          直接重置连接的比例（0-1）
+  --size 字节                响应体大小
+  --cache 值                 X-Cache 响应头，如 HIT
+  --mtls                     HTTPS / HTTP/3 要求客户端证书（同时生成 client.pem）
+
+退出码: 0 成功, 1 错误, 2 回归检查或 SLO 检查未通过, 130 被中断
+`
+
+// stringList 可重复的字符串参数
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// runCLI 解析子命令并执行，返回进程退出码
+func runCLI(args []string) int {
+	if len(args) == 0 {
+		return cmdRun(nil)
+	}
+
+	switch args[0] {
+	case "run":
+		return cmdRun(args[1:])
+	case "validate":
+		return cmdValidate(args[1:])
+	case "report":
+		return cmdReport(args[1:])
+	case "compare":
+		return cmdCompare(args[1:])
+	case "monitor":
+		return cmdMonitor(args[1:])
+	case "history":
+		return cmdHistory(args[1:])
+	case "fakecdn":
+		return cmdFakeCDN(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return exitOK
+	default:
+		// 兼容旧用法: cdn-test config.yaml
+		return cmdRun(args)
+	}
+}
+
+// newFlagSet 创建子命令参数集，出错时打印用法
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(usage) }
+	return fs
+}
+
+// parseArgs 解析参数，允许位置参数与选项交替出现，返回位置参数
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// configFlags run / validate 共用的配置参数
+type configFlags struct {
+	path       string
+	overrides  ConfigOverrides
+	endpoints  stringList
+	thresholds stringList
+}
+
+func (c *configFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.path, "config", defaultConfigPath, "配置文件路径")
+	fs.StringVar(&c.path, "c", defaultConfigPath, "配置文件路径")
+	fs.IntVar(&c.overrides.TestCount, "count", 0, "每个节点测试次数")
+	fs.StringVar(&c.overrides.Interval, "interval", "", "请求间隔")
+	fs.Float64Var(&c.overrides.LoadRPS, "rps", 0, "开环压测：每个节点的目标速率")
+	fs.StringVar(&c.overrides.LoadDuration, "load-duration", "", "开环压测持续时间")
+	fs.StringVar(&c.overrides.Duration, "duration", "", "按时长测试，如 10m")
+	fs.StringVar(&c.overrides.CIWidth, "ci-width", "", "自适应采样的目标置信区间宽度")
+	fs.IntVar(&c.overrides.Concurrency, "concurrency", 0, "每轮每个节点同时发起的请求数")
+	fs.StringVar(&c.overrides.Multiplex, "multiplex", "", "并发请求的连接复用方式")
+	fs.StringVar(&c.overrides.Domain, "domain", "", "测试域名")
+	fs.Var(&c.endpoints, "endpoint", "测试节点 name=ip:proto，可重复")
+	fs.StringVar(&c.overrides.OutputDir, "output", "", "输出目录")
+	fs.StringVar(&c.overrides.Baseline, "baseline", "", "基线 JSON 报告")
+	fs.Var(&c.thresholds, "threshold", "回归阈值，可重复")
+}
+
+// load 解析参数并加载配置
+func (c *configFlags) load(fs *flag.FlagSet, args []string) (*Config, error) {
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	switch len(positional) {
+	case 0:
+	case 1:
+		c.path = positional[0]
+	default:
+		return nil, fmt.Errorf("多余的参数: %s", strings.Join(positional[1:], " "))
+	}
+	c.overrides.Endpoints = c.endpoints
+	c.overrides.Thresholds = c.thresholds
+	return LoadConfig(c.path, c.overrides)
+}
+
+// run 子命令：运行测试
+func cmdRun(args []string) int {
+	fs := newFlagSet("run")
+	var cf configFlags
+	cf.register(fs)
+
+	config, err := cf.load(fs, args)
+	if err != nil {
+		fmt.Printf("❌ 加载配置失败: %v\n", err)
+		fmt.Println("请确保 config.yaml 文件存在，或指定配置文件路径: ./cdn-test run [config.yaml]")
+		return exitError
+	}
+	return runTest(config)
+}
+
+// validate 子命令：只加载并检查配置，不发起网络请求
+func cmdValidate(args []string) int {
+	fs := newFlagSet("validate")
+	var cf configFlags
+	cf.register(fs)
+
+	config, err := cf.load(fs, args)
+	if err != nil {
+		fmt.Printf("❌ 配置无效: %v\n", err)
+		return exitError
+	}
+
+	logger, _ = NewLogger(config.OutputDir, false)
+	logger.LogConfig(*config)
+	fmt.Printf("\n✅ 配置有效: %s\n", cf.path)
+	return exitOK
+}
+
+// monitor 子命令：守护模式
+func cmdMonitor(args []string) int {
+	fs := newFlagSet("monitor")
+	var cf configFlags
+	cf.register(fs)
+	fs.StringVar(&cf.overrides.MonitorListen, "listen", "", "/metrics 监听地址")
+	fs.StringVar(&cf.overrides.MonitorInterval, "every", "", "每轮测试的调度周期")
+
+	config, err := cf.load(fs, args)
+	if err != nil {
+		fmt.Printf("❌ 加载配置失败: %v\n", err)
+		return exitError
+	}
+	return runMonitor(config)
+}
+
+// history 子命令：查询历史趋势
+func cmdHistory(args []string) int {
+	fs := newFlagSet("history")
+	configPath := fs.String("config", defaultConfigPath, "配置文件路径")
+	fs.StringVar(configPath, "c", defaultConfigPath, "配置文件路径")
+	days := fs.Int("days", 30, "查询最近 N 天")
+	filter := fs.String("endpoint", "", "只显示名称包含该字符串的节点")
+	html := fs.Bool("html", false, "生成 HTML 趋势页")
+	dbPath := fs.String("db", "", "历史记录文件")
+	outputDir := fs.String("output", "", "趋势页输出目录")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitError
+	}
+	switch len(positional) {
+	case 0:
+	case 1:
+		*configPath = positional[0]
+	default:
+		fmt.Printf("❌ 多余的参数: %s\n", strings.Join(positional[1:], " "))
+		return exitError
+	}
+	if *days <= 0 {
+		fmt.Printf("❌ --days 必须大于 0，当前为 %d\n", *days)
+		return exitError
+	}
+
+	// 指定 --db 时不需要配置文件
+	if *dbPath == "" {
+		config, err := LoadConfig(*configPath, ConfigOverrides{})
+		if err != nil {
+			fmt.Printf("❌ 加载配置失败: %v\n", err)
+			return exitError
+		}
+		*dbPath = config.HistoryPath
+		if *outputDir == "" {
+			*outputDir = config.OutputDir
+		}
+	}
+	if *outputDir == "" {
+		*outputDir = filepath.Dir(*dbPath)
+	}
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1-*days)
+	runs, skipped, err := LoadHistory(*dbPath, since)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitError
+	}
+	if skipped > 0 {
+		fmt.Printf("⚠️ 跳过 %d 行无法解析的历史记录\n", skipped)
+	}
+	if len(runs) == 0 {
+		fmt.Printf("📭 %s 中没有最近 %d 天的测试记录\n", *dbPath, *days)
+		return exitOK
+	}
+
+	series := dailyTrend(runs, *filter)
+	if len(series) == 0 {
+		fmt.Printf("📭 没有名称包含 %q 的节点\n", *filter)
+		return exitOK
+	}
+	fmt.Printf("🗃️ 历史记录: %s（%d 次运行）\n", *dbPath, len(runs))
+	printTrendTable(series, *days)
+
+	if *html {
+		page := TrendPage{
+			Generated: now,
+			Since:     since,
+			Days:      *days,
+			Runs:      runs,
+			Series:    series,
+			Dates:     trendDates(series),
+			Charts:    trendCharts,
+		}
+		htmlPath, err := ExportTrendHTML(page, *outputDir)
+		if err != nil {
+			fmt.Printf("❌ 生成趋势页失败: %v\n", err)
+			return exitError
+		}
+		fmt.Printf("\n🌐 趋势页: %s\n", htmlPath)
+	}
+	return exitOK
+}
+
+// fakecdn 子命令：启动本地模拟 CDN
+func cmdFakeCDN(args []string) int {
+	fs := newFlagSet("fakecdn")
+	var opts FakeCDNOptions
+	dir := fs.String("dir", "fakecdn", "写入 ca.pem 和 config.yaml 的目录")
+	fs.StringVar(&opts.Host, "host", "127.0.0.1", "监听地址")
+	fs.IntVar(&opts.Port, "port", 0, "HTTPS / HTTP/3 端口")
+	fs.StringVar(&opts.Domain, "domain", defaultFakeCDNDomain, "证书域名")
+	fs.DurationVar(&opts.Delay, "delay", 20*time.Millisecond, "首字节前的固定延迟")
+	fs.DurationVar(&opts.Jitter, "jitter", 10*time.Millisecond, "随机附加延迟上限")
+	fs.DurationVar(&opts.ServerTime, "server-time", 5*time.Millisecond, "模拟源站耗时")
+	fs.Float64Var(&opts.ErrorRate, "error-rate", 0, "返回错误状态码的比例")
+	fs.IntVar(&opts.ErrorStatus, "error-status", 503, "错误状态码")
+	fs.Float64Var(&opts.ResetRate, "reset-rate", 0, "直接重置连接的比例")
+	fs.IntVar(&opts.BodySize, "size", 0, "响应体大小（字节）")
+	fs.StringVar(&opts.CacheStatus, "cache", "", "X-Cache 响应头")
+	fs.BoolVar(&opts.RequireClientCert, "mtls", false, "HTTPS / HTTP/3 要求客户端证书")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitError
+	}
+	if len(positional) > 0 {
+		fmt.Printf("❌ 多余的参数: %s\n", strings.Join(positional, " "))
+		return exitError
+	}
+	switch {
+	case opts.ErrorRate < 0 || opts.ErrorRate > 1:
+		fmt.Printf("❌ --error-rate 必须在 0 到 1 之间，当前为 %g\n", opts.ErrorRate)
+		return exitError
+	case opts.ResetRate < 0 || opts.ResetRate > 1:
+		fmt.Printf("❌ --reset-rate 必须在 0 到 1 之间，当前为 %g\n", opts.ResetRate)
+		return exitError
+	case opts.ErrorStatus < 400 || opts.ErrorStatus > 599:
+		fmt.Printf("❌ --error-status 必须是 4xx 或 5xx，当前为 %d\n", opts.ErrorStatus)
+		return exitError
+	case opts.Delay < 0 || opts.Jitter < 0 || opts.ServerTime < 0 || opts.BodySize < 0:
+		fmt.Println("❌ --delay、--jitter、--server-time、--size 不能小于 0")
+		return exitError
+	}
+	return runFakeCDN(opts, *dir)
+}
+
+// report 子命令：从 JSON 报告重新生成 HTML
+func cmdReport(args []string) int {
+	fs := newFlagSet("report")
+	outputDir := fs.String("output", "", "输出目录（默认与 JSON 报告位于同一 reports 目录）")
+	exportCSV := fs.Bool("csv", false, "同时导出 CSV 原始样本")
+	exportNDJSON := fs.Bool("ndjson", false, "同时导出 NDJSON 原始样本")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitError
+	}
+	if len(positional) != 1 {
+		fmt.Println("❌ 用法: cdn-test report <report.json> [--output 目录] [--csv] [--ndjson]")
+		return exitError
+	}
+
+	report, err := LoadReport(positional[0])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitError
+	}
+
+	// 默认输出到 JSON 所在的 <输出目录>/reports
+	dir := *outputDir
+	if dir == "" {
+		dir = filepath.Dir(filepath.Dir(positional[0]))
+	}
+	htmlPath, err := ExportHTML(report, dir)
+	if err != nil {
+		fmt.Printf("❌ 导出 HTML 报告失败: %v\n", err)
+		return exitError
+	}
+	fmt.Printf("🌐 HTML 报告: %s\n", htmlPath)
+
+	if *exportCSV {
+		csvPath, err := ExportCSV(report, dir)
+		if err != nil {
+			fmt.Printf("❌ 导出 CSV 失败: %v\n", err)
+			return exitError
+		}
+		fmt.Printf("📊 CSV 样本: %s\n", csvPath)
+	}
+	if *exportNDJSON {
+		ndjsonPath, err := ExportNDJSON(report, dir)
+		if err != nil {
+			fmt.Printf("❌ 导出 NDJSON 失败: %v\n", err)
+			return exitError
+		}
+		fmt.Printf("📊 NDJSON 样本: %s\n", ndjsonPath)
+	}
+	return exitOK
+}
+
+// compare 子命令：对比两次测试报告，指定阈值时做回归检查
+func cmdCompare(args []string) int {
+	fs := newFlagSet("compare")
+	configPath := fs.String("config", "", "读取 regression.thresholds 的配置文件")
+	fs.StringVar(configPath, "c", "", "读取 regression.thresholds 的配置文件")
+	var thresholdFlags stringList
+	fs.Var(&thresholdFlags, "threshold", "回归阈值，可重复")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitError
+	}
+	if len(positional) != 2 {
+		fmt.Println("❌ 用法: cdn-test compare <baseline.json> <current.json> [-c config.yaml] [--threshold 规则]")
+		return exitError
+	}
+
+	// 阈值：配置文件中的 regression.thresholds + 命令行参数
+	var thresholds []Threshold
+	if *configPath != "" {
+		config, err := LoadConfig(*configPath, ConfigOverrides{})
+		if err != nil {
+			fmt.Printf("❌ 加载配置失败: %v\n", err)
+			return exitError
+		}
+		thresholds = config.Thresholds
+	}
+	for _, s := range thresholdFlags {
+		th, err := parseThresholdFlag(s)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return exitError
+		}
+		thresholds = append(thresholds, th)
+	}
+
+	baseline, err := LoadReport(positional[0])
+	if err != nil {
+		fmt.Printf("❌ 加载基线报告失败: %v\n", err)
+		return exitError
+	}
+	current, err := LoadReport(positional[1])
+	if err != nil {
+		fmt.Printf("❌ 加载当前报告失败: %v\n", err)
+		return exitError
+	}
+
+	return runComparison(baseline, current, thresholds)
+}
+
+// runComparison 打印对比结果并检查阈值，返回退出码
+func runComparison(baseline, current *TestReport, thresholds []Threshold) int {
+	comparisons := compareReports(baseline, current)
+	printCompareTable(comparisons)
+	printSignificanceTable("🔬 与基线的显著性检验", baselineSignificance(baseline, current))
+	if len(thresholds) == 0 {
+		return exitOK
+	}
+	if failed := printThresholdTable(evaluateThresholds(comparisons, thresholds)); failed > 0 {
+		return exitCheckFailed
+	}
+	return exitOK
+}
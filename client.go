@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptrace"
-	"strconv"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/quic-go/quic-go"
@@ -19,8 +24,70 @@ import (
 // HTTP 客户端
 // ===============================
 
+// ClientOptions 客户端选项
+type ClientOptions struct {
+	SessionResumption bool              // 启用 TLS 会话缓存，新建连接时尝试会话复用
+	EarlyData         bool              // 启用 QUIC 0-RTT 早期数据（仅 HTTP/3，依赖会话复用）
+	ServerName        string            // TLS SNI，为空时使用请求域名
+	RootCAs           *x509.CertPool    // 受信任的 CA，为空时使用系统证书
+	Certificates      []tls.Certificate // 客户端证书（mTLS）
+	Insecure          bool              // 跳过服务端证书校验
+	MaxConnsPerHost   int               // 最多同时建立的连接数（0 不限制，仅 TCP 协议）
+	OnDial            func()            // 新建连接成功后回调（用于统计实际建立的连接数）
+}
+
+// 从配置生成端点的客户端选项
+func newClientOptions(cfg Config, ep Endpoint) ClientOptions {
+	opts := ClientOptions{
+		SessionResumption: cfg.SessionResumption,
+		EarlyData:         cfg.EarlyData,
+		ServerName:        ep.SNI,
+		RootCAs:           ep.Certs.RootCAs,
+		Insecure:          ep.Certs.Insecure,
+	}
+	if ep.Certs.Certificate != nil {
+		opts.Certificates = []tls.Certificate{*ep.Certs.Certificate}
+	}
+	// 并发请求共用一个连接：HTTP/2 在该连接上多路复用，HTTP/1.1 在该连接上排队
+	if cfg.Concurrency > 1 && cfg.Multiplex == SingleConn {
+		opts.MaxConnsPerHost = 1
+	}
+	return opts
+}
+
+// dialed 新建连接成功后调用 OnDial
+func (o ClientOptions) dialed() {
+	if o.OnDial != nil {
+		o.OnDial()
+	}
+}
+
+// loadCertPool 读取 PEM 格式的 CA 证书文件（可包含多个证书），附加到系统证书池之后
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s 中没有有效的 PEM 证书", path)
+	}
+	return pool, nil
+}
+
+// 为每个客户端创建独立的会话缓存，避免不同节点之间共享会话票据
+func (o ClientOptions) sessionCache() tls.ClientSessionCache {
+	if !o.SessionResumption {
+		return nil
+	}
+	return tls.NewLRUClientSessionCache(0)
+}
+
 // 创建 HTTP/1.1 客户端（指定IP）
-func createHTTP1Client(ip string, timeout time.Duration) *http.Client {
+func createHTTP1Client(ip string, timeout time.Duration, opts ClientOptions) *http.Client {
 	dialer := &net.Dialer{
 		Timeout:   timeout,
 		KeepAlive: 30 * time.Second,
@@ -34,17 +101,26 @@ func createHTTP1Client(ip string, timeout time.Duration) *http.Client {
 				port = "443"
 			}
 			// 强制使用指定IP
-			return dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				opts.dialed()
+			}
+			return conn, err
 		},
 		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: false, // 验证证书
+			InsecureSkipVerify: opts.Insecure, // 默认校验证书，仅在显式配置 insecure_skip_verify 时跳过
 			// 强制使用 HTTP/1.1，不进行 HTTP/2 ALPN 协商
-			NextProtos: []string{"http/1.1"},
+			NextProtos:         []string{"http/1.1"},
+			ClientSessionCache: opts.sessionCache(),
+			ServerName:         opts.ServerName,
+			RootCAs:            opts.RootCAs,
+			Certificates:       opts.Certificates,
 		},
 		// 禁用 HTTP/2
 		ForceAttemptHTTP2:   false,
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 100,
+		MaxConnsPerHost:     opts.MaxConnsPerHost,
 		IdleConnTimeout:     90 * time.Second,
 	}
 
@@ -55,7 +131,7 @@ func createHTTP1Client(ip string, timeout time.Duration) *http.Client {
 }
 
 // 创建 HTTP/2 客户端（指定IP，强制使用HTTP/2）
-func createHTTP2Client(ip string, timeout time.Duration) *http.Client {
+func createHTTP2Client(ip string, timeout time.Duration, opts ClientOptions) *http.Client {
 	dialer := &net.Dialer{
 		Timeout:   timeout,
 		KeepAlive: 30 * time.Second,
@@ -69,17 +145,102 @@ func createHTTP2Client(ip string, timeout time.Duration) *http.Client {
 				port = "443"
 			}
 			// 强制使用指定IP
-			return dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				opts.dialed()
+			}
+			return conn, err
 		},
 		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: false,
+			InsecureSkipVerify: opts.Insecure,
 			// 强制使用HTTP/2的ALPN
-			NextProtos: []string{"h2"},
+			NextProtos:         []string{"h2"},
+			ClientSessionCache: opts.sessionCache(),
+			ServerName:         opts.ServerName,
+			RootCAs:            opts.RootCAs,
+			Certificates:       opts.Certificates,
 		},
 		// 强制启用HTTP/2
 		ForceAttemptHTTP2:   true,
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 100,
+		MaxConnsPerHost:     opts.MaxConnsPerHost,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
+
+// 创建明文 HTTP/1.1 客户端（指定IP，opts 中只使用连接数相关选项）
+func createHTTPClient(ip string, timeout time.Duration, opts ClientOptions) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	// 仅启用 HTTP/1.1
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			// 提取端口
+			_, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				port = "80"
+			}
+			// 强制使用指定IP
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				opts.dialed()
+			}
+			return conn, err
+		},
+		Protocols:           &protocols,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		MaxConnsPerHost:     opts.MaxConnsPerHost,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
+
+// 创建 h2c 客户端（指定IP，明文 HTTP/2，不经过 Upgrade 直接发送 HTTP/2 前言）
+func createH2CClient(ip string, timeout time.Duration, opts ClientOptions) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	// 仅启用明文 HTTP/2
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			// 提取端口
+			_, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				port = "80"
+			}
+			// 强制使用指定IP
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				opts.dialed()
+			}
+			return conn, err
+		},
+		Protocols:           &protocols,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		MaxConnsPerHost:     opts.MaxConnsPerHost,
 		IdleConnTimeout:     90 * time.Second,
 	}
 
@@ -90,10 +251,14 @@ func createHTTP2Client(ip string, timeout time.Duration) *http.Client {
 }
 
 // 创建 HTTP/3 客户端（指定IP）
-func createHTTP3Client(ip string, timeout time.Duration) *http.Client {
+func createHTTP3Client(ip string, timeout time.Duration, opts ClientOptions) *http.Client {
 	transport := &http3.Transport{
 		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: false,
+			InsecureSkipVerify: opts.Insecure,
+			ClientSessionCache: opts.sessionCache(),
+			ServerName:         opts.ServerName,
+			RootCAs:            opts.RootCAs,
+			Certificates:       opts.Certificates,
 		},
 		// 自定义 Dial 函数来指定IP
 		Dial: func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (*quic.Conn, error) {
@@ -113,8 +278,37 @@ func createHTTP3Client(ip string, timeout time.Duration) *http.Client {
 			if err != nil {
 				return nil, fmt.Errorf("创建UDP连接失败: %w", err)
 			}
-			// 使用quic.Dial建立连接
-			return quic.Dial(ctx, udpConn, udpAddr, tlsCfg, cfg)
+			// http3.Transport 使用自定义 Dial 时不会触发连接阶段的 trace，这里手动补上
+			trace := httptrace.ContextClientTrace(ctx)
+			if trace != nil && trace.ConnectStart != nil {
+				trace.ConnectStart("udp", targetAddr)
+			}
+			// 使用quic.Dial建立连接（握手完成后返回）
+			// 启用 0-RTT 时使用 DialEarly，可发送早期数据时即返回
+			var conn *quic.Conn
+			if opts.EarlyData {
+				conn, err = quic.DialEarly(ctx, udpConn, udpAddr, tlsCfg, cfg)
+			} else {
+				conn, err = quic.Dial(ctx, udpConn, udpAddr, tlsCfg, cfg)
+			}
+			if trace != nil && trace.ConnectDone != nil {
+				trace.ConnectDone("udp", targetAddr, err)
+			}
+			if err != nil {
+				udpConn.Close()
+				return nil, err
+			}
+			opts.dialed()
+			// 记录新建的 QUIC 连接，供 measureRequest 读取 0-RTT 状态
+			if holder, ok := ctx.Value(quicConnKey{}).(**quic.Conn); ok {
+				*holder = conn
+			}
+			// quic 不会关闭外部传入的 UDP 连接，连接结束后手动关闭，避免冷连接模式下泄漏
+			go func() {
+				<-conn.Context().Done()
+				udpConn.Close()
+			}()
+			return conn, nil
 		},
 	}
 
@@ -128,12 +322,45 @@ func createHTTP3Client(ip string, timeout time.Duration) *http.Client {
 // 测试逻辑
 // ===============================
 
-// 执行单次请求并测量延迟
-func measureRequest(client *http.Client, url string, domain string) RequestResult {
-	result := RequestResult{}
+// RequestOptions 单次请求的测量选项
+type RequestOptions struct {
+	DownloadBody bool  // 是否读取完整响应体
+	MaxBodyBytes int64 // 响应体最多读取字节数（0 表示不限制）
+	EarlyData    bool  // 以 0-RTT 方式发送请求（仅 HTTP/3 客户端可用）
+	Insecure     bool  // 客户端跳过了证书校验（记录到结果中）
+
+	TimingSources []TimingSource    // 服务端耗时来源
+	CacheRules    CacheRules        // 缓存状态识别规则
+	Headers       map[string]string // 附加请求头
+}
+
+// quicConnKey 请求 context 中存放新建 QUIC 连接的键
+type quicConnKey struct{}
 
-	// 创建请求
-	req, err := http.NewRequest("GET", url, nil)
+// 从配置生成端点的请求选项
+func newRequestOptions(cfg Config, ep Endpoint) RequestOptions {
+	return RequestOptions{
+		DownloadBody: cfg.DownloadBody,
+		MaxBodyBytes: cfg.MaxBodyBytes,
+		// 0-RTT 请求只能由 HTTP/3 客户端发送
+		EarlyData:     cfg.EarlyData && ep.Protocol == HTTP3,
+		Insecure:      ep.Certs.Insecure && ep.Protocol.Secure(),
+		TimingSources: cfg.TimingSources,
+		CacheRules:    cfg.CacheRules,
+		Headers:       ep.Headers,
+	}
+}
+
+// 执行单次请求并测量延迟（ctx 取消时中断请求）
+func measureRequest(ctx context.Context, client *http.Client, url string, domain string, opts RequestOptions) RequestResult {
+	result := RequestResult{InsecureSkipVerify: opts.Insecure}
+
+	// 创建请求（0-RTT 请求需使用 http3 的特殊方法名）
+	method := http.MethodGet
+	if opts.EarlyData {
+		method = http3.MethodGet0RTT
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		result.Error = fmt.Sprintf("创建请求失败: %v", err)
 		return result
@@ -143,23 +370,70 @@ func measureRequest(client *http.Client, url string, domain string) RequestResul
 	req.Host = domain
 	// 模拟 Chrome User-Agent
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	// 附加请求头（可覆盖 User-Agent 和 Host）
+	for k, v := range opts.Headers {
+		if strings.EqualFold(k, "Host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
 
-	// 使用 httptrace 测量 TTFB
+	// 使用 httptrace 测量 TTFB 及各连接阶段
 	var start time.Time
 	var ttfb time.Duration
+	var connectStart, tlsStart, gotConnAt, wroteAt time.Time
 
 	var reused bool
+	// HTTP/2 的请求写入和响应读取回调在不同 goroutine 中执行
+	var mu sync.Mutex
 
 	trace := &httptrace.ClientTrace{
+		ConnectStart: func(network, addr string) {
+			connectStart = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err != nil {
+				return
+			}
+			// HTTP/3 的建连即 QUIC 握手（已包含 TLS）
+			if network == "udp" {
+				result.QUICHandshake = time.Since(connectStart)
+			} else {
+				result.TCPConnect = time.Since(connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				result.TLSHandshake = time.Since(tlsStart)
+			}
+		},
 		GotConn: func(connInfo httptrace.GotConnInfo) {
 			reused = connInfo.Reused
+			gotConnAt = time.Now()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			wroteAt = time.Now()
+			result.RequestWrite = wroteAt.Sub(gotConnAt)
 		},
 		GotFirstResponseByte: func() {
+			mu.Lock()
+			defer mu.Unlock()
 			ttfb = time.Since(start)
+			if !wroteAt.IsZero() {
+				result.ServerWait = time.Since(wroteAt)
+			}
 		},
 	}
 
-	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	var quicConn *quic.Conn
+	ctx = context.WithValue(req.Context(), quicConnKey{}, &quicConn)
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
 
 	// 发送请求
 	start = time.Now()
@@ -170,26 +444,90 @@ func measureRequest(client *http.Client, url string, domain string) RequestResul
 	}
 	defer resp.Body.Close()
 
+	mu.Lock()
 	result.TTFB = ttfb
+	mu.Unlock()
 	result.StatusCode = resp.StatusCode
 	result.Reused = reused
 	result.ActualProto = resp.Proto // 记录实际使用的协议版本
 
-	// 提取 x-source-response-time 响应头（单位：秒）
-	xResponseTimeStr := resp.Header.Get("x-source-response-time")
-	if xResponseTimeStr != "" {
-		// 去除可能的 "s" 后缀
-		xResponseTimeStr = strings.TrimSuffix(xResponseTimeStr, "s")
-		xResponseTimeStr = strings.TrimSpace(xResponseTimeStr)
-		if val, err := strconv.ParseFloat(xResponseTimeStr, 64); err == nil {
-			// 转换为毫秒
-			result.XResponseTime = val * 1000
+	// 会话复用和证书信息只对新建连接记录
+	if resp.TLS != nil && !reused {
+		result.TLSResumed = resp.TLS.DidResume
+		result.TLSInfo = newTLSInfo(resp.TLS)
+	}
+	// 0-RTT 状态在握手完成后才能确定
+	if quicConn != nil {
+		select {
+		case <-quicConn.HandshakeComplete():
+			result.EarlyData = quicConn.ConnectionState().Used0RTT
+		case <-quicConn.Context().Done():
 		}
 	}
 
-	// 计算CDN延迟 = TTFB(ms) - x-source-response-time(ms)
+	// 记录全部 Server-Timing 指标，并按配置的来源提取服务端耗时
+	result.ServerTiming = parseServerTiming(resp.Header)
+	if val, source, ok := extractServerTime(resp.Header, result.ServerTiming, opts.TimingSources); ok {
+		result.XResponseTime = val
+		result.ServerTimeSource = source
+	}
+
+	// 识别缓存状态
+	result.CacheStatus, result.CacheHeader = classifyCacheStatus(resp.Header, opts.CacheRules)
+
+	// 计算CDN延迟 = TTFB(ms) - 服务端耗时(ms)
 	ttfbMs := float64(ttfb.Microseconds()) / 1000.0
 	result.CDNLatency = ttfbMs - result.XResponseTime
 
+	// 读取响应体，测量下载耗时和吞吐量
+	if opts.DownloadBody {
+		var body io.Reader = resp.Body
+		if opts.MaxBodyBytes > 0 {
+			body = io.LimitReader(resp.Body, opts.MaxBodyBytes)
+		}
+		n, err := io.Copy(io.Discard, body)
+		result.TotalTime = time.Since(start)
+		result.BodyTransfer = result.TotalTime - ttfb
+		result.BodyBytes = n
+		if err != nil {
+			result.Error = fmt.Sprintf("读取响应体失败: %v", err)
+			return result
+		}
+		if result.TotalTime > 0 {
+			result.Throughput = float64(n) * 8 / result.TotalTime.Seconds() / 1e6
+		}
+	}
+
 	return result
 }
+
+// 从连接状态提取 TLS 与证书信息
+func newTLSInfo(state *tls.ConnectionState) *TLSInfo {
+	info := &TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ALPN:        state.NegotiatedProtocol,
+		OCSPStapled: len(state.OCSPResponse) > 0,
+	}
+	if len(state.PeerCertificates) == 0 {
+		return info
+	}
+
+	leaf := state.PeerCertificates[0]
+	info.Subject = leaf.Subject.CommonName
+	if info.Subject == "" {
+		info.Subject = leaf.Subject.String()
+	}
+	info.Issuer = leaf.Issuer.CommonName
+	if info.Issuer == "" {
+		info.Issuer = leaf.Issuer.String()
+	}
+	info.SANs = append(info.SANs, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	info.NotAfter = leaf.NotAfter
+	sum := sha256.Sum256(leaf.Raw)
+	info.Fingerprint = hex.EncodeToString(sum[:])
+	return info
+}
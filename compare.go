@@ -0,0 +1,473 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ===============================
+// 报告对比
+// ===============================
+
+// summaryMetric 可对比的汇总指标
+type summaryMetric struct {
+	Name           string // 指标名（配置中使用），如 ttfb_p95
+	Label          string // 显示名称
+	Unit           string // 单位: ms / %
+	NeedsCDN       bool   // 是否依赖服务端耗时（未解析到时跳过）
+	HigherIsBetter bool   // 数值越大越好（如成功率）
+	Value          func(s Summary) float64
+}
+
+// summaryMetrics 支持对比的指标，按显示顺序排列
+var summaryMetrics = []summaryMetric{
+	{Name: "success_rate", Label: "成功率", Unit: "%", HigherIsBetter: true, Value: func(s Summary) float64 {
+		return ratioPercent(s.SuccessCount, s.TotalTests)
+	}},
+	{Name: "error_rate", Label: "错误率", Unit: "%", Value: func(s Summary) float64 {
+		return ratioPercent(s.FailCount, s.TotalTests)
+	}},
+	{Name: "ttfb_avg", Label: "TTFB 均值", Unit: "ms", Value: func(s Summary) float64 { return s.TTFBAvg }},
+	{Name: "ttfb_p50", Label: "TTFB P50", Unit: "ms", Value: func(s Summary) float64 { return s.TTFBP50 }},
+	{Name: "ttfb_p90", Label: "TTFB P90", Unit: "ms", Value: func(s Summary) float64 { return s.TTFBP90 }},
+	{Name: "ttfb_p95", Label: "TTFB P95", Unit: "ms", Value: func(s Summary) float64 { return s.TTFBP95 }},
+	{Name: "ttfb_p99", Label: "TTFB P99", Unit: "ms", Value: func(s Summary) float64 { return s.TTFBP99 }},
+	{Name: "cdn_latency_avg", Label: "CDN 延迟均值", Unit: "ms", NeedsCDN: true, Value: func(s Summary) float64 { return s.CDNLatencyAvg }},
+	{Name: "cdn_latency_p50", Label: "CDN 延迟 P50", Unit: "ms", NeedsCDN: true, Value: func(s Summary) float64 { return s.CDNLatencyP50 }},
+	{Name: "cdn_latency_p95", Label: "CDN 延迟 P95", Unit: "ms", NeedsCDN: true, Value: func(s Summary) float64 { return s.CDNLatencyP95 }},
+	{Name: "cdn_latency_p99", Label: "CDN 延迟 P99", Unit: "ms", NeedsCDN: true, Value: func(s Summary) float64 { return s.CDNLatencyP99 }},
+	{Name: "server_time_avg", Label: "服务端均值", Unit: "ms", NeedsCDN: true, Value: func(s Summary) float64 { return s.XResponseTimeAvg }},
+}
+
+// lookupMetric 按名称查找指标
+func lookupMetric(name string) (summaryMetric, bool) {
+	for _, m := range summaryMetrics {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return summaryMetric{}, false
+}
+
+// ratioPercent 计算百分比，总数为 0 时返回 0
+func ratioPercent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
+
+// MetricDelta 单个指标的对比结果
+type MetricDelta struct {
+	Metric   summaryMetric
+	Baseline float64
+	Current  float64
+}
+
+// Change 变化量（当前 - 基线）
+func (d MetricDelta) Change() float64 {
+	return d.Current - d.Baseline
+}
+
+// ChangePercent 相对变化率（%），基线为 0 时返回 NaN
+func (d MetricDelta) ChangePercent() float64 {
+	if d.Baseline == 0 {
+		return math.NaN()
+	}
+	return d.Change() / d.Baseline * 100
+}
+
+// Regressed 是否变差
+func (d MetricDelta) Regressed() bool {
+	if d.Metric.HigherIsBetter {
+		return d.Current < d.Baseline
+	}
+	return d.Current > d.Baseline
+}
+
+// EndpointComparison 单个节点（节点 × 协议 × 目标）的对比结果
+type EndpointComparison struct {
+	Key      string
+	Baseline *Summary // 基线中没有该节点时为 nil
+	Current  *Summary // 当前报告中没有该节点时为 nil
+	Metrics  []MetricDelta
+}
+
+// Label 显示名称
+func (c EndpointComparison) Label() (string, string) {
+	s := c.Current
+	if s == nil {
+		s = c.Baseline
+	}
+	return s.Label(), s.Protocol
+}
+
+// compareReports 按节点键匹配两份报告的汇总，当前报告的顺序在前，仅基线中存在的节点排在最后
+func compareReports(baseline, current *TestReport) []EndpointComparison {
+	baseByKey := make(map[string]*Summary, len(baseline.Summaries))
+	for i := range baseline.Summaries {
+		baseByKey[baseline.Summaries[i].Key()] = &baseline.Summaries[i]
+	}
+
+	var comparisons []EndpointComparison
+	seen := make(map[string]bool)
+	for i := range current.Summaries {
+		cur := &current.Summaries[i]
+		key := cur.Key()
+		seen[key] = true
+		comparisons = append(comparisons, compareSummaries(key, baseByKey[key], cur))
+	}
+	for i := range baseline.Summaries {
+		base := &baseline.Summaries[i]
+		if !seen[base.Key()] {
+			comparisons = append(comparisons, compareSummaries(base.Key(), base, nil))
+		}
+	}
+	return comparisons
+}
+
+// compareSummaries 计算两份汇总的各项指标变化，任一方缺失时不计算
+// 任一方没有成功请求时延迟指标全为 0，不参与对比（成功率 / 错误率照常对比）
+func compareSummaries(key string, baseline, current *Summary) EndpointComparison {
+	c := EndpointComparison{Key: key, Baseline: baseline, Current: current}
+	if baseline == nil || current == nil {
+		return c
+	}
+	for _, m := range summaryMetrics {
+		if m.NeedsCDN && !(baseline.HasCDN && current.HasCDN) {
+			continue
+		}
+		if m.Unit == "ms" && (baseline.SuccessCount == 0 || current.SuccessCount == 0) {
+			continue
+		}
+		c.Metrics = append(c.Metrics, MetricDelta{
+			Metric:   m,
+			Baseline: m.Value(*baseline),
+			Current:  m.Value(*current),
+		})
+	}
+	return c
+}
+
+// 打印对比表格
+func printCompareTable(comparisons []EndpointComparison) {
+	fmt.Println("\n🔀 报告对比 (基线 → 当前):")
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{"节点", "协议", "指标", "基线", "当前", "变化", "变化率"}),
+	)
+
+	for _, c := range comparisons {
+		name, proto := c.Label()
+		switch {
+		case c.Baseline == nil:
+			table.Append([]string{name, proto, "-", "-", "-", "仅当前报告", "-"})
+			continue
+		case c.Current == nil:
+			table.Append([]string{name, proto, "-", "-", "-", "仅基线报告", "-"})
+			continue
+		}
+		for _, d := range c.Metrics {
+			pct := "-"
+			if p := d.ChangePercent(); !math.IsNaN(p) {
+				pct = fmt.Sprintf("%+.1f%%", p)
+			}
+			mark := ""
+			if d.Change() != 0 {
+				mark = " ✓"
+				if d.Regressed() {
+					mark = " ✗"
+				}
+			}
+			table.Append([]string{
+				name,
+				proto,
+				d.Metric.Label,
+				fmt.Sprintf("%.2f%s", d.Baseline, d.Metric.Unit),
+				fmt.Sprintf("%.2f%s", d.Current, d.Metric.Unit),
+				fmt.Sprintf("%+.2f%s", d.Change(), d.Metric.Unit),
+				pct + mark,
+			})
+		}
+	}
+
+	table.Render()
+	fmt.Println("\n💡 说明: 节点按 名称 + 协议 + 测试目标 匹配")
+	fmt.Println("   - 变化 = 当前 - 基线，✓ 表示变好，✗ 表示变差")
+	fmt.Println("   - CDN 延迟和服务端耗时仅在两份报告都解析到服务端耗时时对比")
+}
+
+// ===============================
+// 回归阈值
+// ===============================
+
+// Threshold 回归检查规则，可同时设置多个条件
+type Threshold struct {
+	Metric        string   // 指标名，见 summaryMetrics
+	MaxRegression *float64 // 相对基线最多变差的百分比，如 15 表示 P95 最多变慢 15%
+	Min           *float64 // 当前值下限（如成功率 ≥ 99.5）
+	Max           *float64 // 当前值上限（如 TTFB P99 ≤ 300）
+}
+
+// String 规则描述，如 "TTFB P95 回归 ≤ 15%"
+func (t Threshold) String() string {
+	m, ok := lookupMetric(t.Metric)
+	if !ok {
+		m.Label = t.Metric
+	}
+	var parts []string
+	if t.MaxRegression != nil {
+		parts = append(parts, fmt.Sprintf("回归 ≤ %g%%", *t.MaxRegression))
+	}
+	if t.Min != nil {
+		parts = append(parts, fmt.Sprintf("≥ %g%s", *t.Min, m.Unit))
+	}
+	if t.Max != nil {
+		parts = append(parts, fmt.Sprintf("≤ %g%s", *t.Max, m.Unit))
+	}
+	return m.Label + " " + strings.Join(parts, ", ")
+}
+
+// parsePercent 解析百分比，"15%" 与 "15" 等价
+func parsePercent(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("无效的百分比 %q", s)
+	}
+	return v, nil
+}
+
+// toThreshold 转换 YAML 中的阈值配置
+func (t yamlThreshold) toThreshold() (Threshold, error) {
+	th := Threshold{Metric: t.Metric, Min: t.Min, Max: t.Max}
+	if t.MaxRegression != "" {
+		v, err := parsePercent(t.MaxRegression)
+		if err != nil {
+			return Threshold{}, err
+		}
+		th.MaxRegression = &v
+	}
+	return th, nil
+}
+
+// toYAML 转回 YAML 配置形式，用于把命令行阈值合并到配置中统一校验
+func (t Threshold) toYAML() yamlThreshold {
+	yt := yamlThreshold{Metric: t.Metric, Min: t.Min, Max: t.Max}
+	if t.MaxRegression != nil {
+		yt.MaxRegression = strconv.FormatFloat(*t.MaxRegression, 'f', -1, 64)
+	}
+	return yt
+}
+
+// parseThresholdFlag 解析 --threshold 参数
+// 格式: ttfb_p95:+15%（相对基线最多变差 15%）、success_rate>=99.5、ttfb_p99<=300
+func parseThresholdFlag(s string) (Threshold, error) {
+	var th Threshold
+	var value string
+	switch {
+	case strings.Contains(s, ">="):
+		th.Metric, value, _ = strings.Cut(s, ">=")
+	case strings.Contains(s, "<="):
+		th.Metric, value, _ = strings.Cut(s, "<=")
+	case strings.Contains(s, ":"):
+		th.Metric, value, _ = strings.Cut(s, ":")
+	default:
+		return Threshold{}, fmt.Errorf("无效的 --threshold %q，格式如 ttfb_p95:+15%%、success_rate>=99.5、ttfb_p99<=300", s)
+	}
+	th.Metric = strings.TrimSpace(th.Metric)
+	if _, ok := lookupMetric(th.Metric); !ok {
+		return Threshold{}, fmt.Errorf("未知指标 %q，可选值: %s", th.Metric, strings.Join(metricNames(), ", "))
+	}
+
+	v, err := parsePercent(strings.TrimPrefix(strings.TrimSpace(value), "+"))
+	if err != nil {
+		return Threshold{}, fmt.Errorf("无效的 --threshold %q: %w", s, err)
+	}
+	switch {
+	case strings.Contains(s, ">="):
+		th.Min = &v
+	case strings.Contains(s, "<="):
+		th.Max = &v
+	default:
+		th.MaxRegression = &v
+	}
+	return th, nil
+}
+
+// metricNames 全部指标名
+func metricNames() []string {
+	names := make([]string, len(summaryMetrics))
+	for i, m := range summaryMetrics {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// RegressionPercent 相对基线变差的百分比（变好时为负），基线为 0 且当前变差时为 +Inf
+func (d MetricDelta) RegressionPercent() float64 {
+	change := d.Change()
+	if d.Metric.HigherIsBetter {
+		change = -change
+	}
+	if d.Baseline == 0 {
+		if change > 0 {
+			return math.Inf(1)
+		}
+		return 0
+	}
+	return change / math.Abs(d.Baseline) * 100
+}
+
+// ThresholdResult 单条规则在单个节点上的检查结果
+type ThresholdResult struct {
+	Key       string
+	Label     string
+	Protocol  string
+	Threshold Threshold
+	Baseline  *float64 // 基线中没有该节点时为 nil
+	Current   float64
+	Missing   bool // 当前报告中缺少该节点、延迟指标没有成功请求、缺少服务端耗时或指标未知（没有当前值）
+	Passed    bool
+	Reason    string // 未通过原因
+}
+
+// evaluateThresholds 对每个节点逐条检查阈值
+// 相对回归需要基线和当前报告都有该节点；上下限只检查当前值
+// 基线中有而当前报告中缺少的节点、没有成功请求时的延迟指标（全为 0）、
+// 基线有服务端耗时而当前没有时的 CDN 指标、未知指标均判为未通过
+func evaluateThresholds(comparisons []EndpointComparison, thresholds []Threshold) []ThresholdResult {
+	var results []ThresholdResult
+	for _, c := range comparisons {
+		if c.Current == nil {
+			for _, th := range thresholds {
+				results = append(results, ThresholdResult{
+					Key:       c.Key,
+					Label:     c.Baseline.Label(),
+					Protocol:  c.Baseline.Protocol,
+					Threshold: th,
+					Missing:   true,
+					Reason:    "当前报告中缺少该节点",
+				})
+			}
+			continue
+		}
+		for _, th := range thresholds {
+			r := ThresholdResult{
+				Key:       c.Key,
+				Label:     c.Current.Label(),
+				Protocol:  c.Current.Protocol,
+				Threshold: th,
+				Passed:    true,
+			}
+			m, ok := lookupMetric(th.Metric)
+			if !ok {
+				r.Passed, r.Missing = false, true
+				r.Reason = fmt.Sprintf("未知指标 %q", th.Metric)
+				results = append(results, r)
+				continue
+			}
+			// 当前没有服务端耗时：基线也没有时不检查（如未经过 CDN），基线有时说明响应头丢失
+			if m.NeedsCDN && !c.Current.HasCDN {
+				if c.Baseline == nil || !c.Baseline.HasCDN {
+					continue
+				}
+				r.Passed, r.Missing = false, true
+				r.Reason = "当前报告中没有服务端耗时"
+				if c.Current.SuccessCount == 0 {
+					r.Reason = "没有成功的请求"
+				}
+				if c.Baseline.SuccessCount > 0 {
+					base := m.Value(*c.Baseline)
+					r.Baseline = &base
+				}
+				results = append(results, r)
+				continue
+			}
+			r.Current = m.Value(*c.Current)
+
+			// 基线没有成功请求时延迟指标无意义，只检查上下限
+			if c.Baseline != nil && !(m.NeedsCDN && !c.Baseline.HasCDN) && !(m.Unit == "ms" && c.Baseline.SuccessCount == 0) {
+				base := m.Value(*c.Baseline)
+				r.Baseline = &base
+			}
+
+			if m.Unit == "ms" && c.Current.SuccessCount == 0 {
+				r.Passed, r.Missing = false, true
+				r.Reason = "没有成功的请求"
+				results = append(results, r)
+				continue
+			}
+
+			var reasons []string
+			if th.MaxRegression != nil && r.Baseline != nil {
+				d := MetricDelta{Metric: m, Baseline: *r.Baseline, Current: r.Current}
+				if reg := d.RegressionPercent(); reg > *th.MaxRegression {
+					reasons = append(reasons, fmt.Sprintf("回归 %.1f%% > %g%%", reg, *th.MaxRegression))
+				}
+			}
+			if th.Min != nil && r.Current < *th.Min {
+				reasons = append(reasons, fmt.Sprintf("%.2f%s < %g%s", r.Current, m.Unit, *th.Min, m.Unit))
+			}
+			if th.Max != nil && r.Current > *th.Max {
+				reasons = append(reasons, fmt.Sprintf("%.2f%s > %g%s", r.Current, m.Unit, *th.Max, m.Unit))
+			}
+			if len(reasons) > 0 {
+				r.Passed = false
+				r.Reason = strings.Join(reasons, "; ")
+			}
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// 打印回归检查表格，返回未通过的数量
+func printThresholdTable(results []ThresholdResult) int {
+	fmt.Println("\n🚦 回归检查:")
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{"节点", "协议", "规则", "基线", "当前", "结果"}),
+	)
+
+	failed := 0
+	for _, r := range results {
+		m, _ := lookupMetric(r.Threshold.Metric)
+		base := "-"
+		if r.Baseline != nil {
+			base = fmt.Sprintf("%.2f%s", *r.Baseline, m.Unit)
+		}
+		current := "-"
+		if !r.Missing {
+			current = fmt.Sprintf("%.2f%s", r.Current, m.Unit)
+		}
+		verdict := "✅ 通过"
+		if !r.Passed {
+			verdict = "❌ " + r.Reason
+			failed++
+		}
+		table.Append([]string{
+			r.Label,
+			r.Protocol,
+			r.Threshold.String(),
+			base,
+			current,
+			verdict,
+		})
+	}
+
+	table.Render()
+	fmt.Println("\n💡 说明: 回归百分比 = 相对基线变差的幅度（成功率下降、延迟上升均为变差）")
+	fmt.Println("   - 基线中没有的节点只检查上下限")
+	fmt.Println("   - 当前报告中缺少的节点、没有成功请求时的延迟指标、基线有而当前缺少服务端耗时的 CDN 指标均判为未通过")
+	if failed > 0 {
+		fmt.Printf("\n❌ 回归检查未通过: %d/%d 项\n", failed, len(results))
+	} else {
+		fmt.Printf("\n✅ 回归检查通过: %d 项\n", len(results))
+	}
+	return failed
+}
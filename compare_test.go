@@ -0,0 +1,198 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseThresholdFlag(t *testing.T) {
+	tests := []struct {
+		flag          string
+		metric        string
+		maxRegression *float64
+		min, max      *float64
+		wantErr       bool
+	}{
+		{flag: "ttfb_p95:+15%", metric: "ttfb_p95", maxRegression: ptr(15.0)},
+		{flag: "ttfb_p50:10", metric: "ttfb_p50", maxRegression: ptr(10.0)},
+		{flag: "success_rate>=99.5", metric: "success_rate", min: ptr(99.5)},
+		{flag: " ttfb_p99 <= 300 ", metric: "ttfb_p99", max: ptr(300.0)},
+
+		{flag: "ttfb_p95", wantErr: true},
+		{flag: "ttfb_p95=15", wantErr: true},
+		{flag: "unknown:+15%", wantErr: true},
+		{flag: "ttfb_p95:abc", wantErr: true},
+		{flag: "success_rate>=", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			th, err := parseThresholdFlag(tt.flag)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望解析失败，实际得到 %s", th)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if th.Metric != tt.metric || !equalPtr(th.MaxRegression, tt.maxRegression) || !equalPtr(th.Min, tt.min) || !equalPtr(th.Max, tt.max) {
+				t.Errorf("得到 %s, 期望指标 %s 回归 %v 下限 %v 上限 %v", th, tt.metric, deref(tt.maxRegression), deref(tt.min), deref(tt.max))
+			}
+		})
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func equalPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func deref(p *float64) interface{} {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// testSummary 构造汇总：total 个请求中 success 个成功，TTFB P95 为 p95
+func testSummary(name string, total, success int, p95 float64) Summary {
+	return Summary{
+		EndpointName: name,
+		Protocol:     "HTTP/2",
+		TotalTests:   total,
+		SuccessCount: success,
+		FailCount:    total - success,
+		TTFBP95:      p95,
+	}
+}
+
+func TestEvaluateThresholds(t *testing.T) {
+	thresholds := []Threshold{
+		{Metric: "ttfb_p95", MaxRegression: ptr(15), Max: ptr(300)},
+		{Metric: "success_rate", Min: ptr(99)},
+	}
+	baseline := &TestReport{Summaries: []Summary{
+		testSummary("稳定", 100, 100, 100),
+		testSummary("变慢", 100, 100, 100),
+		testSummary("全部失败", 100, 100, 100),
+		testSummary("基线失败", 100, 0, 0),
+		testSummary("已下线", 100, 100, 100),
+	}}
+	current := &TestReport{Summaries: []Summary{
+		testSummary("稳定", 100, 100, 110),
+		testSummary("变慢", 100, 100, 120),
+		testSummary("全部失败", 100, 0, 0),
+		testSummary("基线失败", 100, 100, 250),
+		testSummary("新节点", 100, 99, 400),
+	}}
+
+	type check struct {
+		passed  bool
+		missing bool
+		reason  string // 未通过原因包含的内容
+	}
+	want := map[string][]check{
+		"稳定": {{passed: true}, {passed: true}},
+		"变慢": {{reason: "回归 20.0% > 15%"}, {passed: true}},
+		// 没有成功请求时延迟指标全为 0，不能视为变快
+		"全部失败": {{missing: true, reason: "没有成功的请求"}, {reason: "0.00% < 99%"}},
+		// 基线没有成功请求时不检查回归，只检查上下限
+		"基线失败": {{passed: true}, {passed: true}},
+		// 没有基线时只检查上下限
+		"新节点": {{reason: "400.00ms > 300ms"}, {passed: true}},
+		"已下线": {{missing: true, reason: "当前报告中缺少该节点"}, {missing: true, reason: "当前报告中缺少该节点"}},
+	}
+
+	results := evaluateThresholds(compareReports(baseline, current), thresholds)
+	got := make(map[string][]ThresholdResult)
+	for _, r := range results {
+		got[r.Label] = append(got[r.Label], r)
+	}
+	if len(got) != len(want) {
+		t.Errorf("检查了 %d 个节点, 期望 %d", len(got), len(want))
+	}
+	for label, checks := range want {
+		if len(got[label]) != len(checks) {
+			t.Errorf("%s: 检查结果 %d 条, 期望 %d 条", label, len(got[label]), len(checks))
+			continue
+		}
+		for i, c := range checks {
+			r := got[label][i]
+			if r.Passed != c.passed || r.Missing != c.missing || !strings.Contains(r.Reason, c.reason) {
+				t.Errorf("%s %s: 通过 %v 缺失 %v 原因 %q, 期望通过 %v 缺失 %v 原因包含 %q",
+					label, r.Threshold, r.Passed, r.Missing, r.Reason, c.passed, c.missing, c.reason)
+			}
+		}
+	}
+
+	if r := got["基线失败"][0]; r.Baseline != nil {
+		t.Errorf("基线没有成功请求时不应记录基线值，实际 %g", *r.Baseline)
+	}
+	if r := got["稳定"][0]; r.Baseline == nil || *r.Baseline != 100 || r.Current != 110 {
+		t.Errorf("稳定: 基线 %v 当前 %g, 期望 100 → 110", deref(r.Baseline), r.Current)
+	}
+}
+
+func TestEvaluateThresholdsServerTime(t *testing.T) {
+	withCDN := func(s Summary, latency float64) Summary {
+		s.HasCDN = true
+		s.CDNLatencyP95 = latency
+		return s
+	}
+	thresholds := []Threshold{
+		{Metric: "cdn_latency_p95", MaxRegression: ptr(15)},
+		{Metric: "ttfb_p100", Max: ptr(300)},
+	}
+	baseline := &TestReport{Summaries: []Summary{
+		withCDN(testSummary("稳定", 100, 100, 100), 50),
+		withCDN(testSummary("丢失响应头", 100, 100, 100), 50),
+		testSummary("未经过 CDN", 100, 100, 100),
+	}}
+	current := &TestReport{Summaries: []Summary{
+		withCDN(testSummary("稳定", 100, 100, 100), 55),
+		testSummary("丢失响应头", 100, 100, 100),
+		testSummary("未经过 CDN", 100, 100, 100),
+	}}
+
+	type check struct {
+		passed  bool
+		missing bool
+		reason  string
+	}
+	unknown := check{missing: true, reason: `未知指标 "ttfb_p100"`}
+	want := map[string][]check{
+		"稳定": {{passed: true}, unknown},
+		// 基线有服务端耗时而当前没有，不能因为缺少数据跳过检查
+		"丢失响应头": {{missing: true, reason: "当前报告中没有服务端耗时"}, unknown},
+		// 两边都没有服务端耗时时不检查 CDN 指标
+		"未经过 CDN": {unknown},
+	}
+
+	got := make(map[string][]ThresholdResult)
+	for _, r := range evaluateThresholds(compareReports(baseline, current), thresholds) {
+		got[r.Label] = append(got[r.Label], r)
+	}
+	for label, checks := range want {
+		if len(got[label]) != len(checks) {
+			t.Errorf("%s: 检查结果 %d 条, 期望 %d 条", label, len(got[label]), len(checks))
+			continue
+		}
+		for i, c := range checks {
+			r := got[label][i]
+			if r.Passed != c.passed || r.Missing != c.missing || !strings.Contains(r.Reason, c.reason) {
+				t.Errorf("%s %s: 通过 %v 缺失 %v 原因 %q, 期望通过 %v 缺失 %v 原因包含 %q",
+					label, r.Threshold, r.Passed, r.Missing, r.Reason, c.passed, c.missing, c.reason)
+			}
+		}
+	}
+	if r := got["丢失响应头"][0]; r.Baseline == nil || *r.Baseline != 50 {
+		t.Errorf("丢失响应头: 基线 = %v, 期望 50", deref(r.Baseline))
+	}
+}
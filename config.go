@@ -1,8 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -24,11 +34,56 @@ type Config struct {
 	Interval  time.Duration // 请求间隔
 	Endpoints []Endpoint    // 待测试的endpoint列表
 
+	ConnectionMode ConnectionMode // 连接模式（热连接/冷连接/混合）
+	TimingSources  []TimingSource // 服务端耗时来源，按顺序取第一个命中的
+	CacheRules     CacheRules     // 缓存状态识别规则
+
+	// 并发测试
+	Concurrency int       // 每轮每个节点同时发起的请求数（默认 1）
+	Multiplex   Multiplex // 并发请求的连接复用方式
+
+	// 按时长测试与自适应采样（均为按轮次测试）
+	Duration time.Duration  // 持续发起轮次直到达到该时长（0 表示按 test_count 结束）
+	Adaptive AdaptiveConfig // 自适应采样：各节点置信区间达到目标宽度后停止
+
+	// 开环压测（LoadRPS > 0 时按固定速率发送，代替按轮次测试）
+	LoadRPS      float64       // 每个节点的目标发送速率（每秒请求数）
+	LoadDuration time.Duration // 压测持续时间
+
+	// TLS 配置
+	SessionResumption bool       // 启用 TLS 会话缓存（会话复用）
+	EarlyData         bool       // 启用 QUIC 0-RTT（仅 HTTP/3）
+	CertExpiryDays    int        // 证书剩余有效期少于该天数时告警
+	Certs             CertConfig // 全局证书校验与客户端证书（端点可覆盖）
+
+	// 响应体下载配置
+	DownloadBody bool  // 是否读取完整响应体（测量下载耗时和吞吐量）
+	MaxBodyBytes int64 // 响应体最多读取字节数（0 表示不限制）
+
+	// 测试目标（至少一个，未配置 targets 时为全局 domain + 端点 path）
+	Targets []Target
+
 	// 输出配置
-	OutputDir  string // 输出目录
-	EnableLog  bool   // 是否启用日志
-	EnableJSON bool   // 是否生成 JSON 报告
-	EnableHTML bool   // 是否生成 HTML 报告
+	OutputDir    string // 输出目录
+	EnableLog    bool   // 是否启用日志
+	EnableJSON   bool   // 是否生成 JSON 报告
+	EnableHTML   bool   // 是否生成 HTML 报告
+	EnableCSV    bool   // 是否导出 CSV 原始样本（每行一个请求）
+	EnableNDJSON bool   // 是否导出 NDJSON 原始样本（每行一个请求）
+
+	// 回归检查
+	Baseline   string      // 基线报告路径（为空时不做回归检查）
+	Thresholds []Threshold // 回归阈值
+
+	SLOs []SLO // 全局 SLO 断言
+
+	// 历史记录
+	HistoryEnabled bool   // 每次测试结束后追加到历史记录
+	HistoryPath    string // 历史记录文件
+
+	// 守护模式（monitor 子命令）
+	MonitorListen   string        // /metrics 监听地址
+	MonitorInterval time.Duration // 每轮测试的调度周期
 }
 
 // Endpoint 端点配置
@@ -36,6 +91,72 @@ type Endpoint struct {
 	IP       string   // IP地址
 	Protocol Protocol // 协议类型
 	Name     string   // 名称（用于显示）
+
+	// 以下为可覆盖项，加载配置时已合并全局值
+	Scheme  string            // URL scheme（默认 https，明文协议默认 http）
+	Port    int               // 端口（0 表示使用 scheme 默认端口）
+	Path    string            // 请求路径
+	SNI     string            // TLS SNI（为空时使用域名）
+	Headers map[string]string // 附加请求头（全局 headers 与端点 headers 合并）
+	Timeout time.Duration     // 请求超时
+	SLOs    []SLO             // SLO 断言（全局 slo 与端点 slo 合并）
+	Certs   CertConfig        // 证书校验与客户端证书（端点 tls 覆盖全局 tls）
+}
+
+// CertConfig 证书校验与客户端证书
+type CertConfig struct {
+	CAFile     string // 额外信任的 CA 证书文件（为空时只使用系统证书）
+	ClientCert string // 客户端证书文件（mTLS）
+	ClientKey  string // 客户端私钥文件
+	Insecure   bool   // 跳过服务端证书校验
+
+	RootCAs     *x509.CertPool   // 由 CAFile 加载的证书池（系统证书 + CAFile）
+	Certificate *tls.Certificate // 由 ClientCert / ClientKey 加载的客户端证书
+}
+
+// String 证书配置摘要（用于日志），未配置任何项时为空
+func (c CertConfig) String() string {
+	var parts []string
+	if c.CAFile != "" {
+		parts = append(parts, "CA: "+c.CAFile)
+	}
+	if c.ClientCert != "" {
+		parts = append(parts, "客户端证书: "+c.ClientCert)
+	}
+	if c.Insecure {
+		parts = append(parts, "⚠️ 跳过证书校验")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Target 测试目标（同一批节点上测试的不同资源）
+type Target struct {
+	Name   string // 名称（用于显示，未配置 targets 时为空）
+	Domain string // 域名（默认使用全局 domain）
+	Path   string // 请求路径（为空时使用端点 path）
+}
+
+// URL 生成该端点访问指定目标的请求地址
+func (e Endpoint) URL(t Target) string {
+	host := t.Domain
+	if e.Port != 0 {
+		host = net.JoinHostPort(t.Domain, strconv.Itoa(e.Port))
+	}
+	path := t.Path
+	if path == "" {
+		path = e.Path
+	}
+	return fmt.Sprintf("%s://%s%s", e.Scheme, host, path)
+}
+
+// BaseTarget 全局 domain + 端点 path 对应的目标
+func (c Config) BaseTarget() Target {
+	return Target{Domain: c.Domain}
+}
+
+// HasTargets 是否配置了 targets（配置后结果按目标区分）
+func (c Config) HasTargets() bool {
+	return len(c.Targets) > 0 && c.Targets[0].Name != ""
 }
 
 // Protocol 协议类型
@@ -45,6 +166,8 @@ const (
 	HTTP1 Protocol = iota
 	HTTP2
 	HTTP3
+	HTTPPlain // 明文 HTTP/1.1
+	H2C       // 明文 HTTP/2（prior knowledge）
 )
 
 func (p Protocol) String() string {
@@ -55,20 +178,144 @@ func (p Protocol) String() string {
 		return "HTTP/2"
 	case HTTP3:
 		return "HTTP/3"
+	case HTTPPlain:
+		return "HTTP/1.1 明文"
+	case H2C:
+		return "HTTP/2 明文"
 	default:
 		return "Unknown"
 	}
 }
 
-// parseProtocol 解析协议字符串
+// Secure 是否使用 TLS
+func (p Protocol) Secure() bool {
+	return p != HTTPPlain && p != H2C
+}
+
+// parseProtocol 解析协议字符串，无法识别时为 HTTP/1.1
 func parseProtocol(s string) Protocol {
+	p, _ := lookupProtocol(s)
+	return p
+}
+
+// lookupProtocol 解析协议字符串，返回是否可识别（空字符串视为 HTTP/1.1）
+func lookupProtocol(s string) (Protocol, bool) {
 	switch s {
 	case "HTTP/3", "http3", "h3":
-		return HTTP3
+		return HTTP3, true
 	case "HTTP/2", "http2", "h2":
-		return HTTP2
+		return HTTP2, true
+	case "HTTP/1.1", "http1.1", "http1", "h1", "":
+		return HTTP1, true
+	case "http", "HTTP/1.1 明文":
+		return HTTPPlain, true
+	case "h2c", "HTTP/2 明文":
+		return H2C, true
+	default:
+		return HTTP1, false
+	}
+}
+
+// ConnectionMode 连接模式
+type ConnectionMode int
+
+const (
+	WarmConnection  ConnectionMode = iota // 复用已有连接（默认）
+	ColdConnection                        // 每次请求都新建 TCP/TLS 或 QUIC 连接
+	MixedConnection                       // 奇数轮冷连接，偶数轮热连接
+)
+
+func (m ConnectionMode) String() string {
+	switch m {
+	case WarmConnection:
+		return "warm"
+	case ColdConnection:
+		return "cold"
+	case MixedConnection:
+		return "mixed"
+	default:
+		return "unknown"
+	}
+}
+
+// parseConnectionMode 解析连接模式字符串
+func parseConnectionMode(s string) ConnectionMode {
+	m, _ := lookupConnectionMode(s)
+	return m
+}
+
+// lookupConnectionMode 解析连接模式，返回是否可识别（空字符串视为 warm）
+func lookupConnectionMode(s string) (ConnectionMode, bool) {
+	switch s {
+	case "cold":
+		return ColdConnection, true
+	case "mixed":
+		return MixedConnection, true
+	case "warm", "":
+		return WarmConnection, true
+	default:
+		return WarmConnection, false
+	}
+}
+
+// Multiplex 同一节点并发请求的连接复用方式
+type Multiplex int
+
+const (
+	SingleConn     Multiplex = iota // 共用一个连接（HTTP/2、HTTP/3 多路复用，HTTP/1.1 在该连接上排队）
+	PerRequestConn                  // 每个并发请求使用独立的连接
+)
+
+func (m Multiplex) String() string {
+	switch m {
+	case SingleConn:
+		return "single_conn"
+	case PerRequestConn:
+		return "per_request_conn"
+	default:
+		return "unknown"
+	}
+}
+
+// parseMultiplex 解析连接复用方式字符串
+func parseMultiplex(s string) Multiplex {
+	m, _ := lookupMultiplex(s)
+	return m
+}
+
+// lookupMultiplex 解析连接复用方式，返回是否可识别（空字符串视为 single_conn）
+func lookupMultiplex(s string) (Multiplex, bool) {
+	switch s {
+	case "per_request_conn":
+		return PerRequestConn, true
+	case "single_conn", "":
+		return SingleConn, true
+	default:
+		return SingleConn, false
+	}
+}
+
+// coldRound 判断某一轮是否需要强制新建连接
+func (m ConnectionMode) coldRound(round int) bool {
+	switch m {
+	case ColdConnection:
+		return true
+	case MixedConnection:
+		return round%2 == 1
 	default:
-		return HTTP1
+		return false
+	}
+}
+
+// roundLimit 按轮次测试的最大轮次，0 表示不限（按 duration 结束）
+func (c Config) roundLimit() int {
+	switch {
+	case c.Adaptive.Enabled():
+		return c.Adaptive.MaxCount
+	case c.Duration > 0:
+		return 0
+	default:
+		return c.TestCount
 	}
 }
 
@@ -77,26 +324,239 @@ func parseProtocol(s string) Protocol {
 // ===============================
 
 type yamlConfig struct {
-	Domain    string `yaml:"domain"`
-	Path      string `yaml:"path"`
-	TestCount int    `yaml:"test_count"`
-	Timeout   string `yaml:"timeout"`
-	Interval  string `yaml:"interval"`
-	Endpoints []struct {
-		Name     string `yaml:"name"`
-		IP       string `yaml:"ip"`
-		Protocol string `yaml:"protocol"`
-	} `yaml:"endpoints"`
+	Domain      string            `yaml:"domain"`
+	Path        string            `yaml:"path"`
+	TestCount   int               `yaml:"test_count"`
+	Timeout     string            `yaml:"timeout"`
+	Interval    string            `yaml:"interval"`
+	Headers     map[string]string `yaml:"headers"`
+	ConnMode    string            `yaml:"connection_mode"`
+	Concurrency int               `yaml:"concurrency"`
+	Multiplex   string            `yaml:"multiplex"`
+	Duration    string            `yaml:"duration"`
+	Adaptive    struct {
+		CIWidth  string `yaml:"ci_width"`
+		MinCount int    `yaml:"min_count"`
+		MaxCount int    `yaml:"max_count"`
+	} `yaml:"adaptive"`
+	Load struct {
+		RPS      float64 `yaml:"rps"`
+		Duration string  `yaml:"duration"`
+	} `yaml:"load"`
+	Targets []struct {
+		Name   string `yaml:"name"`
+		Domain string `yaml:"domain"`
+		Path   string `yaml:"path"`
+	} `yaml:"targets"`
+	Timing []struct {
+		Header  string `yaml:"header"`
+		Metric  string `yaml:"metric"`
+		Unit    string `yaml:"unit"`
+		Pattern string `yaml:"pattern"`
+	} `yaml:"server_timing"`
+	Cache struct {
+		Headers  []string            `yaml:"headers"`
+		Keywords map[string][]string `yaml:"keywords"`
+	} `yaml:"cache_status"`
+	TLS struct {
+		SessionResumption bool `yaml:"session_resumption"`
+		EarlyData         bool `yaml:"early_data"`
+		CertExpiryDays    *int `yaml:"cert_expiry_warn_days"`
+		yamlCerts         `yaml:",inline"`
+	} `yaml:"tls"`
+	Body struct {
+		Download bool  `yaml:"download"`
+		MaxBytes int64 `yaml:"max_bytes"`
+	} `yaml:"body"`
+	Endpoints  []yamlEndpoint `yaml:"endpoints"`
+	SLO        []string       `yaml:"slo"`
+	Regression struct {
+		Baseline   string          `yaml:"baseline"`
+		Thresholds []yamlThreshold `yaml:"thresholds"`
+	} `yaml:"regression"`
+	History struct {
+		Enabled bool   `yaml:"enabled"`
+		Path    string `yaml:"path"`
+	} `yaml:"history"`
+	Monitor struct {
+		Listen   string `yaml:"listen"`
+		Interval string `yaml:"interval"`
+	} `yaml:"monitor"`
 	Output struct {
-		Dir        string `yaml:"dir"`
-		EnableLog  bool   `yaml:"enable_log"`
-		EnableJSON bool   `yaml:"enable_json"`
-		EnableHTML bool   `yaml:"enable_html"`
+		Dir          string `yaml:"dir"`
+		EnableLog    bool   `yaml:"enable_log"`
+		EnableJSON   bool   `yaml:"enable_json"`
+		EnableHTML   bool   `yaml:"enable_html"`
+		EnableCSV    bool   `yaml:"enable_csv"`
+		EnableNDJSON bool   `yaml:"enable_ndjson"`
 	} `yaml:"output"`
 }
 
-// LoadConfig 从 YAML 文件加载配置
-func LoadConfig(path string) (*Config, error) {
+type yamlEndpoint struct {
+	Name     string            `yaml:"name"`
+	IP       string            `yaml:"ip"`
+	Protocol string            `yaml:"protocol"`
+	Scheme   string            `yaml:"scheme"`
+	Port     int               `yaml:"port"`
+	Path     string            `yaml:"path"`
+	SNI      string            `yaml:"sni"`
+	Headers  map[string]string `yaml:"headers"`
+	Timeout  string            `yaml:"timeout"`
+	SLO      []string          `yaml:"slo"`
+	TLS      yamlCerts         `yaml:"tls"`
+}
+
+// yamlCerts 证书配置，全局 tls 和端点 tls 共用
+type yamlCerts struct {
+	CAFile             string `yaml:"ca_file"`
+	ClientCert         string `yaml:"client_cert"`
+	ClientKey          string `yaml:"client_key"`
+	InsecureSkipVerify *bool  `yaml:"insecure_skip_verify"`
+}
+
+// apply 用配置中出现的项覆盖 base，只加载被覆盖的证书文件
+func (y yamlCerts) apply(base CertConfig) (CertConfig, error) {
+	c := base
+	if y.CAFile != "" {
+		pool, err := loadCertPool(y.CAFile)
+		if err != nil {
+			return c, err
+		}
+		c.CAFile, c.RootCAs = y.CAFile, pool
+	}
+	if y.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(y.ClientCert, y.ClientKey)
+		if err != nil {
+			return c, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		c.ClientCert, c.ClientKey, c.Certificate = y.ClientCert, y.ClientKey, &cert
+	}
+	if y.InsecureSkipVerify != nil {
+		c.Insecure = *y.InsecureSkipVerify
+	}
+	return c, nil
+}
+
+type yamlThreshold struct {
+	Metric        string   `yaml:"metric"`
+	MaxRegression string   `yaml:"max_regression"`
+	Min           *float64 `yaml:"min"`
+	Max           *float64 `yaml:"max"`
+}
+
+// ConfigOverrides 命令行参数对配置文件的覆盖，零值表示不覆盖
+type ConfigOverrides struct {
+	TestCount  int      // --count
+	Interval   string   // --interval
+	Domain     string   // --domain
+	Endpoints  []string // --endpoint name=ip:proto，可重复，指定后替换配置文件中的节点
+	OutputDir  string   // --output
+	Baseline   string   // --baseline
+	Thresholds []string // --threshold，可重复，追加到配置文件中的阈值之后
+
+	LoadRPS      float64 // --rps
+	LoadDuration string  // --load-duration
+	Concurrency  int     // --concurrency
+	Multiplex    string  // --multiplex
+	Duration     string  // --duration
+	CIWidth      string  // --ci-width
+
+	MonitorListen   string // monitor --listen
+	MonitorInterval string // monitor --every
+}
+
+// apply 在转换配置前覆盖 YAML 中的值，使默认值和端点继承逻辑照常生效
+// 返回被覆盖的顶层配置项
+func (o ConfigOverrides) apply(yc *yamlConfig) (map[string]bool, error) {
+	overridden := make(map[string]bool)
+	if o.TestCount > 0 {
+		yc.TestCount = o.TestCount
+		overridden["test_count"] = true
+	}
+	if o.Interval != "" {
+		yc.Interval = o.Interval
+		overridden["interval"] = true
+	}
+	if o.Domain != "" {
+		yc.Domain = o.Domain
+		overridden["domain"] = true
+	}
+	if o.Concurrency > 0 {
+		yc.Concurrency = o.Concurrency
+		overridden["concurrency"] = true
+	}
+	if o.Multiplex != "" {
+		yc.Multiplex = o.Multiplex
+		overridden["multiplex"] = true
+	}
+	if o.Duration != "" {
+		yc.Duration = o.Duration
+		overridden["duration"] = true
+	}
+	if o.CIWidth != "" {
+		yc.Adaptive.CIWidth = o.CIWidth
+		overridden["adaptive"] = true
+	}
+	if o.LoadRPS > 0 {
+		yc.Load.RPS = o.LoadRPS
+		overridden["load"] = true
+	}
+	if o.LoadDuration != "" {
+		yc.Load.Duration = o.LoadDuration
+		overridden["load"] = true
+	}
+	if o.OutputDir != "" {
+		yc.Output.Dir = o.OutputDir
+	}
+	if o.Baseline != "" {
+		yc.Regression.Baseline = o.Baseline
+	}
+	if o.MonitorListen != "" {
+		yc.Monitor.Listen = o.MonitorListen
+		overridden["monitor"] = true
+	}
+	if o.MonitorInterval != "" {
+		yc.Monitor.Interval = o.MonitorInterval
+		overridden["monitor"] = true
+	}
+	for _, s := range o.Thresholds {
+		th, err := parseThresholdFlag(s)
+		if err != nil {
+			return nil, err
+		}
+		yc.Regression.Thresholds = append(yc.Regression.Thresholds, th.toYAML())
+	}
+	if len(o.Endpoints) > 0 {
+		yc.Endpoints = make([]yamlEndpoint, 0, len(o.Endpoints))
+		for _, s := range o.Endpoints {
+			ep, err := parseEndpointFlag(s)
+			if err != nil {
+				return nil, err
+			}
+			yc.Endpoints = append(yc.Endpoints, ep)
+		}
+		overridden["endpoints"] = true
+	}
+	return overridden, nil
+}
+
+// parseEndpointFlag 解析 --endpoint 参数，格式 name=ip:proto，如 "节点A=1.2.3.4:h2"
+// IP 可以是 IPv6，以最后一个冒号分隔协议
+func parseEndpointFlag(s string) (yamlEndpoint, error) {
+	name, rest, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return yamlEndpoint{}, fmt.Errorf("无效的 --endpoint %q，格式应为 name=ip:proto", s)
+	}
+	i := strings.LastIndex(rest, ":")
+	if i <= 0 || i == len(rest)-1 {
+		return yamlEndpoint{}, fmt.Errorf("无效的 --endpoint %q，格式应为 name=ip:proto", s)
+	}
+	ip := strings.Trim(rest[:i], "[]")
+	return yamlEndpoint{Name: name, IP: ip, Protocol: rest[i+1:]}, nil
+}
+
+// LoadConfig 从 YAML 文件加载配置，并应用命令行覆盖
+func LoadConfig(path string, overrides ConfigOverrides) (*Config, error) {
 	if path == "" {
 		path = defaultConfigPath
 	}
@@ -106,31 +566,214 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
+	// 严格解码：未知配置项和类型错误会被收集，与后续校验的问题一起报告
 	var yc yamlConfig
-	if err := yaml.Unmarshal(data, &yc); err != nil {
+	var errs ConfigErrors
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&yc); err != nil && !errors.Is(err, io.EOF) {
+		var te *yaml.TypeError
+		if !errors.As(err, &te) {
+			return nil, fmt.Errorf("解析配置文件失败: %w", err)
+		}
+		errs = append(errs, typeErrorsToConfigErrors(te)...)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
-	// 解析超时时间
+	overridden, err := overrides.apply(&yc)
+	if err != nil {
+		return nil, err
+	}
+	errs = append(errs, validateConfig(&yc, yamlLocator{root: &root, overridden: overridden})...)
+	if len(errs) > 0 {
+		errs.sortByLine()
+		return nil, errs
+	}
+
+	// 解析超时时间（已校验，未配置时默认 30s）
 	timeout, err := time.ParseDuration(yc.Timeout)
 	if err != nil {
 		timeout = 30 * time.Second
 	}
 
-	// 解析请求间隔
+	// 解析请求间隔（已校验，未配置时默认 100ms）
 	interval, err := time.ParseDuration(yc.Interval)
 	if err != nil {
 		interval = 100 * time.Millisecond
 	}
 
-	// 转换端点配置
+	// 并发数，未配置时每轮每个节点一个请求
+	concurrency := yc.Concurrency
+	if concurrency == 0 {
+		concurrency = 1
+	}
+
+	// 按时长测试（已校验，未配置时按 test_count 结束）
+	duration, _ := time.ParseDuration(yc.Duration)
+
+	// 自适应采样（已校验），未配置 max_count 时以 test_count 为上限，配置了 duration 时不限
+	var adaptive AdaptiveConfig
+	if yc.Adaptive.CIWidth != "" {
+		adaptive.Width, adaptive.Relative, err = parseCIWidth(yc.Adaptive.CIWidth)
+		if err != nil {
+			return nil, err
+		}
+		adaptive.MinCount = yc.Adaptive.MinCount
+		if adaptive.MinCount == 0 {
+			adaptive.MinCount = defaultAdaptiveMinCount
+		}
+		adaptive.MaxCount = yc.Adaptive.MaxCount
+		if adaptive.MaxCount == 0 && duration == 0 {
+			adaptive.MaxCount = yc.TestCount
+		}
+	}
+
+	// 开环压测持续时间（已校验，未配置时默认 30s）
+	loadDuration, err := time.ParseDuration(yc.Load.Duration)
+	if err != nil {
+		loadDuration = 30 * time.Second
+	}
+
+	// 解析服务端耗时来源，未配置时使用 x-source-response-time
+	timingSources := defaultTimingSources
+	if len(yc.Timing) > 0 {
+		timingSources = make([]TimingSource, 0, len(yc.Timing))
+		for _, t := range yc.Timing {
+			src := TimingSource{Header: t.Header, Metric: t.Metric, Unit: t.Unit}
+			if t.Pattern != "" {
+				re, err := regexp.Compile(t.Pattern)
+				if err != nil {
+					return nil, fmt.Errorf("解析 server_timing.pattern 失败 (%s): %w", t.Header, err)
+				}
+				src.Pattern = re
+			}
+			timingSources = append(timingSources, src)
+		}
+	}
+
+	// 缓存状态识别规则，未配置的部分使用默认值
+	cacheRules := CacheRules{
+		Headers:  defaultCacheRules.Headers,
+		Keywords: make(map[string][]string),
+	}
+	if len(yc.Cache.Headers) > 0 {
+		cacheRules.Headers = yc.Cache.Headers
+	}
+	for status, keywords := range defaultCacheRules.Keywords {
+		cacheRules.Keywords[status] = keywords
+	}
+	for status, keywords := range yc.Cache.Keywords {
+		cacheRules.Keywords[strings.ToUpper(status)] = keywords
+	}
+
+	// 证书过期告警天数，未配置时默认 30 天
+	certExpiryDays := 30
+	if yc.TLS.CertExpiryDays != nil {
+		certExpiryDays = *yc.TLS.CertExpiryDays
+	}
+
+	// 全局证书配置（已校验）
+	globalCerts, err := yc.TLS.yamlCerts.apply(CertConfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	// SLO 断言（已校验）
+	globalSLOs, err := parseSLOs(yc.SLO, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// 转换端点配置，未覆盖的项使用全局值
 	endpoints := make([]Endpoint, len(yc.Endpoints))
 	for i, ep := range yc.Endpoints {
-		endpoints[i] = Endpoint{
+		endpoint := Endpoint{
 			Name:     ep.Name,
 			IP:       ep.IP,
 			Protocol: parseProtocol(ep.Protocol),
+			Scheme:   ep.Scheme,
+			Port:     ep.Port,
+			Path:     ep.Path,
+			SNI:      ep.SNI,
+			Timeout:  timeout,
+		}
+		if endpoint.Scheme == "" {
+			endpoint.Scheme = "https"
+			if !endpoint.Protocol.Secure() {
+				endpoint.Scheme = "http"
+			}
 		}
+		if endpoint.Path == "" {
+			endpoint.Path = yc.Path
+		}
+		if ep.Timeout != "" {
+			if t, err := time.ParseDuration(ep.Timeout); err == nil {
+				endpoint.Timeout = t
+			}
+		}
+
+		// 合并请求头，端点配置优先
+		if len(yc.Headers) > 0 || len(ep.Headers) > 0 {
+			endpoint.Headers = make(map[string]string, len(yc.Headers)+len(ep.Headers))
+			for k, v := range yc.Headers {
+				endpoint.Headers[k] = v
+			}
+			for k, v := range ep.Headers {
+				endpoint.Headers[k] = v
+			}
+		}
+
+		// 合并 SLO，端点断言覆盖全局同一指标的断言
+		slos, err := parseSLOs(ep.SLO, false)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.SLOs = mergeSLOs(globalSLOs, slos)
+
+		// 证书配置，端点 tls 覆盖全局 tls
+		endpoint.Certs, err = ep.TLS.apply(globalCerts)
+		if err != nil {
+			return nil, err
+		}
+		endpoints[i] = endpoint
+	}
+
+	// 转换测试目标，未配置时只测试全局 domain
+	targets := []Target{{Domain: yc.Domain}}
+	if len(yc.Targets) > 0 {
+		targets = make([]Target, len(yc.Targets))
+		for i, t := range yc.Targets {
+			targets[i] = Target{Name: t.Name, Domain: t.Domain, Path: t.Path}
+			if targets[i].Domain == "" {
+				targets[i].Domain = yc.Domain
+			}
+			if targets[i].Name == "" {
+				targets[i].Name = targets[i].Domain + targets[i].Path
+			}
+		}
+	}
+
+	// 回归阈值（已校验）
+	thresholds := make([]Threshold, 0, len(yc.Regression.Thresholds))
+	for _, t := range yc.Regression.Thresholds {
+		th, err := t.toThreshold()
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, th)
+	}
+
+	// 守护模式，未配置时监听 :9108，每 30 秒一轮
+	monitorListen := yc.Monitor.Listen
+	if monitorListen == "" {
+		monitorListen = ":9108"
+	}
+	monitorInterval, err := time.ParseDuration(yc.Monitor.Interval)
+	if err != nil {
+		monitorInterval = 30 * time.Second
 	}
 
 	// 设置默认值
@@ -139,16 +782,47 @@ func LoadConfig(path string) (*Config, error) {
 		outputDir = "./output"
 	}
 
+	// 历史记录默认保存在输出目录下
+	historyPath := yc.History.Path
+	if historyPath == "" {
+		historyPath = filepath.Join(outputDir, defaultHistoryFile)
+	}
+
 	return &Config{
-		Domain:     yc.Domain,
-		Path:       yc.Path,
-		TestCount:  yc.TestCount,
-		Timeout:    timeout,
-		Interval:   interval,
-		Endpoints:  endpoints,
-		OutputDir:  outputDir,
-		EnableLog:  yc.Output.EnableLog,
-		EnableJSON: yc.Output.EnableJSON,
-		EnableHTML: yc.Output.EnableHTML,
+		Domain:            yc.Domain,
+		Path:              yc.Path,
+		TestCount:         yc.TestCount,
+		Timeout:           timeout,
+		Interval:          interval,
+		Endpoints:         endpoints,
+		Targets:           targets,
+		ConnectionMode:    parseConnectionMode(yc.ConnMode),
+		Concurrency:       concurrency,
+		Multiplex:         parseMultiplex(yc.Multiplex),
+		Duration:          duration,
+		Adaptive:          adaptive,
+		LoadRPS:           yc.Load.RPS,
+		LoadDuration:      loadDuration,
+		TimingSources:     timingSources,
+		CacheRules:        cacheRules,
+		SessionResumption: yc.TLS.SessionResumption,
+		EarlyData:         yc.TLS.EarlyData,
+		CertExpiryDays:    certExpiryDays,
+		Certs:             globalCerts,
+		DownloadBody:      yc.Body.Download,
+		MaxBodyBytes:      yc.Body.MaxBytes,
+		OutputDir:         outputDir,
+		EnableLog:         yc.Output.EnableLog,
+		EnableJSON:        yc.Output.EnableJSON,
+		EnableHTML:        yc.Output.EnableHTML,
+		EnableCSV:         yc.Output.EnableCSV,
+		EnableNDJSON:      yc.Output.EnableNDJSON,
+		Baseline:          yc.Regression.Baseline,
+		Thresholds:        thresholds,
+		SLOs:              globalSLOs,
+		HistoryEnabled:    yc.History.Enabled,
+		HistoryPath:       historyPath,
+		MonitorListen:     monitorListen,
+		MonitorInterval:   monitorInterval,
 	}, nil
 }
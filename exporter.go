@@ -6,7 +6,8 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -16,29 +17,87 @@ import (
 
 // TestReport 完整测试报告
 type TestReport struct {
-	StartTime           time.Time                  `json:"start_time"` // 测试开始时间
-	EndTime             time.Time                  `json:"end_time"`   // 测试结束时间
-	Duration            time.Duration              `json:"duration"`   // 总耗时
-	Config              ReportConfig               `json:"config"`     // 测试配置快照
-	Results             map[string][]RequestResult `json:"results"`    // 按 endpoint 分组的详细结果
-	Summaries           []Summary                  `json:"summaries"`  // 汇总统计
-	SummariesByProtocol map[string][]Summary       `json:"-"`          // 按协议分组（仅用于 HTML 渲染）
-	Protocols           []string                   `json:"-"`          // 协议列表（保持顺序）
+	StartTime            time.Time                  `json:"start_time"`                      // 测试开始时间
+	EndTime              time.Time                  `json:"end_time"`                        // 测试结束时间
+	Duration             time.Duration              `json:"duration"`                        // 总耗时
+	Config               ReportConfig               `json:"config"`                          // 测试配置快照
+	Results              map[string][]RequestResult `json:"results"`                         // 按 endpoint 分组的详细结果
+	Summaries            []Summary                  `json:"summaries"`                       // 汇总统计
+	Warnings             []string                   `json:"warnings"`                        // 告警信息（证书不一致、即将过期等）
+	Partial              bool                       `json:"partial"`                         // 是否被中断（仅包含已完成的轮次）
+	CompletedRounds      int                        `json:"completed_rounds"`                // 已完成的轮次
+	LoadElapsed          time.Duration              `json:"load_elapsed,omitempty"`          // 开环压测实际持续时长
+	TLS                  map[string]*TLSInfo        `json:"tls,omitempty"`                   // 按 endpoint × target 记录的 TLS/证书信息（取首个新建连接）
+	Significance         []SignificanceTest         `json:"significance"`                    // 同一目标下节点两两 TTFB 显著性检验
+	SLOVerdicts          []SLOVerdict               `json:"slo_verdicts,omitempty"`          // SLO 判定结果
+	BaselineSignificance []SignificanceTest         `json:"baseline_significance,omitempty"` // 与基线报告的显著性检验
+	SummariesByProtocol  map[string][]Summary       `json:"-"`                               // 按协议分组（仅用于 HTML 渲染）
+	Protocols            []string                   `json:"-"`                               // 协议列表（保持顺序）
+	SummariesByTarget    map[string][]Summary       `json:"-"`                               // 按测试目标分组（仅用于 HTML 渲染）
 }
 
 // ReportConfig 配置快照（用于报告）
 type ReportConfig struct {
-	Domain    string         `json:"domain"`
-	Path      string         `json:"path"`
-	TestCount int            `json:"test_count"`
-	Endpoints []EndpointInfo `json:"endpoints"`
+	Domain         string         `json:"domain"`
+	Path           string         `json:"path"`
+	TestCount      int            `json:"test_count"`                   // 计划轮次（自适应采样时为上限，按时长测试不限轮次时为 0）
+	Duration       time.Duration  `json:"duration,omitempty"`           // 按时长测试的计划时长
+	CIWidth        string         `json:"ci_width,omitempty"`           // 自适应采样的目标置信区间宽度
+	AdaptiveMin    int            `json:"adaptive_min_count,omitempty"` // 自适应采样的最少轮次
+	ConnectionMode string         `json:"connection_mode"`
+	Concurrency    int            `json:"concurrency"`             // 每轮每个节点同时发起的请求数
+	Multiplex      string         `json:"multiplex"`               // 并发请求的连接复用方式
+	LoadRPS        float64        `json:"load_rps,omitempty"`      // 开环压测目标速率（按轮次测试时为 0）
+	LoadDuration   time.Duration  `json:"load_duration,omitempty"` // 开环压测计划时长
+	SessionResume  bool           `json:"session_resumption"`
+	EarlyData      bool           `json:"early_data"`
+	DownloadBody   bool           `json:"download_body"`
+	MaxBodyBytes   int64          `json:"max_body_bytes,omitempty"`
+	CertExpiryDays int            `json:"cert_expiry_warn_days"`
+	TimingSources  []string       `json:"timing_sources"`
+	Targets        []TargetInfo   `json:"targets,omitempty"` // 测试目标（未配置 targets 时为空）
+	Endpoints      []EndpointInfo `json:"endpoints"`
+}
+
+// TargetInfo 测试目标信息（用于报告）
+type TargetInfo struct {
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
+	Path   string `json:"path"`
 }
 
 // EndpointInfo 端点信息（用于报告）
 type EndpointInfo struct {
-	Name     string `json:"name"`
-	IP       string `json:"ip"`
-	Protocol string `json:"protocol"`
+	Name     string        `json:"name"`
+	IP       string        `json:"ip"`
+	Protocol string        `json:"protocol"`
+	URL      string        `json:"url"`               // 基础请求地址（含端口和路径覆盖，配置 targets 时按目标替换域名和路径）
+	SNI      string        `json:"sni,omitempty"`     // SNI 覆盖
+	Headers  []string      `json:"headers,omitempty"` // 附加请求头名称（不记录值，避免泄露凭据）
+	Timeout  time.Duration `json:"timeout"`           // 请求超时
+	TLS      *TLSInfo      `json:"tls,omitempty"`     // 旧版本报告的 TLS/证书信息（新报告按测试组合记录在 TestReport.TLS）
+
+	CAFile             string `json:"ca_file,omitempty"`              // 额外信任的 CA 证书
+	ClientCert         string `json:"client_cert,omitempty"`          // 客户端证书（mTLS，不记录私钥）
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"` // 跳过了服务端证书校验
+}
+
+// Verification 证书校验方式，如 "系统 CA"、"自定义 CA + mTLS"、"⚠️ 已跳过"
+func (e EndpointInfo) Verification() string {
+	if !parseProtocol(e.Protocol).Secure() {
+		return "-"
+	}
+	v := "系统 CA"
+	switch {
+	case e.InsecureSkipVerify:
+		v = "⚠️ 已跳过"
+	case e.CAFile != "":
+		v = "自定义 CA"
+	}
+	if e.ClientCert != "" {
+		v += " + mTLS"
+	}
+	return v
 }
 
 // NewTestReport 创建新的测试报告
@@ -49,16 +108,70 @@ func NewTestReport(startTime time.Time, cfg Config) *TestReport {
 			Name:     ep.Name,
 			IP:       ep.IP,
 			Protocol: ep.Protocol.String(),
+			URL:      ep.URL(cfg.BaseTarget()),
+			SNI:      ep.SNI,
+			Timeout:  ep.Timeout,
+		}
+		if ep.Protocol.Secure() {
+			endpoints[i].CAFile = ep.Certs.CAFile
+			endpoints[i].ClientCert = ep.Certs.ClientCert
+			endpoints[i].InsecureSkipVerify = ep.Certs.Insecure
+		}
+		for name := range ep.Headers {
+			endpoints[i].Headers = append(endpoints[i].Headers, name)
+		}
+		sort.Strings(endpoints[i].Headers)
+	}
+
+	var targets []TargetInfo
+	if cfg.HasTargets() {
+		for _, t := range cfg.Targets {
+			targets = append(targets, TargetInfo{Name: t.Name, Domain: t.Domain, Path: t.Path})
+		}
+	}
+
+	timingSources := make([]string, len(cfg.TimingSources))
+	for i, src := range cfg.TimingSources {
+		timingSources[i] = src.Header
+		if src.Metric != "" {
+			timingSources[i] += ":" + src.Metric
 		}
 	}
 
+	// 按轮次测试时不记录压测时长
+	var loadDuration time.Duration
+	if cfg.LoadRPS > 0 {
+		loadDuration = cfg.LoadDuration
+	}
+
+	var ciWidth string
+	var adaptiveMin int
+	if cfg.Adaptive.Enabled() {
+		ciWidth, adaptiveMin = cfg.Adaptive.String(), cfg.Adaptive.MinCount
+	}
+
 	return &TestReport{
 		StartTime: startTime,
 		Config: ReportConfig{
-			Domain:    cfg.Domain,
-			Path:      cfg.Path,
-			TestCount: cfg.TestCount,
-			Endpoints: endpoints,
+			Domain:         cfg.Domain,
+			Path:           cfg.Path,
+			TestCount:      cfg.roundLimit(),
+			Duration:       cfg.Duration,
+			CIWidth:        ciWidth,
+			AdaptiveMin:    adaptiveMin,
+			ConnectionMode: cfg.ConnectionMode.String(),
+			Concurrency:    cfg.Concurrency,
+			Multiplex:      cfg.Multiplex.String(),
+			LoadRPS:        cfg.LoadRPS,
+			LoadDuration:   loadDuration,
+			SessionResume:  cfg.SessionResumption,
+			EarlyData:      cfg.EarlyData,
+			DownloadBody:   cfg.DownloadBody,
+			MaxBodyBytes:   cfg.MaxBodyBytes,
+			CertExpiryDays: cfg.CertExpiryDays,
+			TimingSources:  timingSources,
+			Targets:        targets,
+			Endpoints:      endpoints,
 		},
 		Results:             make(map[string][]RequestResult),
 		SummariesByProtocol: make(map[string][]Summary),
@@ -70,11 +183,24 @@ func (r *TestReport) Finalize(summaries []Summary) {
 	r.EndTime = time.Now()
 	r.Duration = r.EndTime.Sub(r.StartTime)
 	r.Summaries = summaries
+	r.groupSummaries()
+	r.Significance = pairwiseSignificance(r.Summaries, r.Results)
+
+	// 证书检查
+	r.Warnings = append(r.Warnings, checkCertificates(r.TLSRows(), r.Config.CertExpiryDays, r.EndTime)...)
+	r.Warnings = append(r.Warnings, checkInsecure(r.Summaries, r.Results)...)
+}
 
+// groupSummaries 按协议和测试目标分组汇总（仅用于 HTML 渲染，不导出到 JSON）
+func (r *TestReport) groupSummaries() {
 	// 按协议分组
-	protocolOrder := []string{"HTTP/3", "HTTP/2", "HTTP/1.1"}
+	protocolOrder := []string{
+		HTTP3.String(), HTTP2.String(), HTTP1.String(),
+		H2C.String(), HTTPPlain.String(),
+	}
 	r.SummariesByProtocol = make(map[string][]Summary)
-	for _, s := range summaries {
+	r.Protocols = nil
+	for _, s := range r.Summaries {
 		r.SummariesByProtocol[s.Protocol] = append(r.SummariesByProtocol[s.Protocol], s)
 	}
 	// 只保留有数据的协议
@@ -83,6 +209,77 @@ func (r *TestReport) Finalize(summaries []Summary) {
 			r.Protocols = append(r.Protocols, p)
 		}
 	}
+
+	// 按测试目标分组
+	r.SummariesByTarget = make(map[string][]Summary)
+	for _, s := range r.Summaries {
+		if s.Target != "" {
+			r.SummariesByTarget[s.Target] = append(r.SummariesByTarget[s.Target], s)
+		}
+	}
+}
+
+// LoadReport 读取 ExportJSON 导出的报告
+func LoadReport(path string) (*TestReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取报告失败: %w", err)
+	}
+
+	var report TestReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("解析报告失败 (%s): %w", path, err)
+	}
+	report.groupSummaries()
+	// 旧版本报告没有显著性检验结果或多重比较校正，根据详细结果重新计算（结果确定，与原报告一致）
+	if len(report.Results) > 0 {
+		report.Significance = pairwiseSignificance(report.Summaries, report.Results)
+	}
+	// 与基线的检验需要基线报告的详细结果，只根据保存的 p 值重新校正
+	holmAdjust(report.BaselineSignificance)
+	return &report, nil
+}
+
+// RunID 测试运行标识，即报告文件名中的时间戳，如 2024-01-02_15-04-05
+func (r *TestReport) RunID() string {
+	return r.StartTime.Format("2006-01-02_15-04-05")
+}
+
+// reportKey 结果在报告中的键，如 "节点A (HTTP/2)"，配置 targets 时为 "节点A (HTTP/2) [首页]"
+func reportKey(endpoint Endpoint, target Target) string {
+	key := fmt.Sprintf("%s (%s)", endpoint.Name, endpoint.Protocol)
+	if target.Name != "" {
+		key += " [" + target.Name + "]"
+	}
+	return key
+}
+
+// SetCompletedRounds 记录已完成的轮次，被中断时标记为部分结果
+// 按时长测试和自适应采样可能在计划轮次之前正常结束，不视为部分结果
+func (r *TestReport) SetCompletedRounds(completedRounds int, interrupted bool) {
+	r.CompletedRounds = completedRounds
+	if interrupted {
+		r.Partial = true
+		r.Warnings = append(r.Warnings, fmt.Sprintf("测试被中断，仅包含已完成的 %s 轮结果", r.roundsText()))
+	}
+}
+
+// roundsText 已完成 / 计划轮次，如 "3/10"，不限轮次时只有已完成轮次
+func (r *TestReport) roundsText() string {
+	if r.Config.TestCount == 0 {
+		return fmt.Sprintf("%d", r.CompletedRounds)
+	}
+	return fmt.Sprintf("%d/%d", r.CompletedRounds, r.Config.TestCount)
+}
+
+// SetLoadElapsed 记录开环压测实际持续的时长，被中断时标记为部分结果
+// 发送计划完成后、请求返回前被中断时时长已满，但进行中的请求被丢弃，同样是部分结果
+func (r *TestReport) SetLoadElapsed(elapsed time.Duration, interrupted bool) {
+	r.LoadElapsed = elapsed
+	if interrupted {
+		r.Partial = true
+		r.Warnings = append(r.Warnings, fmt.Sprintf("测试被中断，仅包含前 %s/%s 已返回的压测结果（进行中的请求已丢弃）", elapsed.Round(time.Second), r.Config.LoadDuration))
+	}
 }
 
 // AddResults 添加端点测试结果
@@ -90,6 +287,47 @@ func (r *TestReport) AddResults(endpointName string, results []RequestResult) {
 	r.Results[endpointName] = results
 }
 
+// SetTLSInfo 按报告键记录测试组合的 TLS/证书信息（取首个带 TLS 信息的结果）
+// 配置多个 targets 时不同域名的证书各自记录
+func (r *TestReport) SetTLSInfo(endpoint Endpoint, target Target, results []RequestResult) {
+	for _, res := range results {
+		if res.TLSInfo != nil {
+			if r.TLS == nil {
+				r.TLS = make(map[string]*TLSInfo)
+			}
+			r.TLS[reportKey(endpoint, target)] = res.TLSInfo
+			return
+		}
+	}
+}
+
+// TLSRow TLS 表格中的一行（节点 × 协议 × 目标）
+type TLSRow struct {
+	Label    string // 节点名称（配置 targets 时附带目标名）
+	Target   string // 测试目标名称（未配置 targets 时为空）
+	Endpoint EndpointInfo
+	TLS      *TLSInfo // 没有 TLS 信息时为 nil
+}
+
+// TLSRows 按汇总顺序列出每个测试组合的 TLS 信息
+// 旧版本报告只按节点记录，取端点信息中的 TLS
+func (r *TestReport) TLSRows() []TLSRow {
+	endpoints := make(map[string]EndpointInfo, len(r.Config.Endpoints))
+	for _, ep := range r.Config.Endpoints {
+		endpoints[ep.Name+"\x00"+ep.Protocol] = ep
+	}
+	rows := make([]TLSRow, 0, len(r.Summaries))
+	for _, s := range r.Summaries {
+		ep := endpoints[s.EndpointName+"\x00"+s.Protocol]
+		info := r.TLS[s.Key()]
+		if info == nil {
+			info = ep.TLS
+		}
+		rows = append(rows, TLSRow{Label: s.Label(), Target: s.Target, Endpoint: ep, TLS: info})
+	}
+	return rows
+}
+
 // ExportJSON 导出 JSON 格式报告
 func ExportJSON(report *TestReport, outputDir string) (string, error) {
 	// 创建报告目录
@@ -99,8 +337,7 @@ func ExportJSON(report *TestReport, outputDir string) (string, error) {
 	}
 
 	// 生成文件名
-	timestamp := report.StartTime.Format("2006-01-02_15-04-05")
-	filePath := filepath.Join(reportDir, fmt.Sprintf("%s.json", timestamp))
+	filePath := filepath.Join(reportDir, report.RunID()+".json")
 
 	// 序列化为 JSON
 	data, err := json.MarshalIndent(report, "", "  ")
@@ -125,8 +362,7 @@ func ExportHTML(report *TestReport, outputDir string) (string, error) {
 	}
 
 	// 生成文件名
-	timestamp := report.StartTime.Format("2006-01-02_15-04-05")
-	filePath := filepath.Join(reportDir, fmt.Sprintf("%s.html", timestamp))
+	filePath := filepath.Join(reportDir, report.RunID()+".html")
 
 	// 创建文件
 	file, err := os.Create(filePath)
@@ -136,7 +372,21 @@ func ExportHTML(report *TestReport, outputDir string) (string, error) {
 	defer file.Close()
 
 	// 解析模板并渲染
-	tmpl, err := template.New("report").Funcs(template.FuncMap{
+	tmpl, err := newReportTemplate("report", htmlTemplate)
+	if err != nil {
+		return "", fmt.Errorf("解析 HTML 模板失败: %w", err)
+	}
+
+	if err := tmpl.Execute(file, report); err != nil {
+		return "", fmt.Errorf("渲染 HTML 模板失败: %w", err)
+	}
+
+	return filePath, nil
+}
+
+// templateFuncs HTML 模板函数（报告页和趋势页共用）
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
 		"formatDuration": func(d time.Duration) string {
 			return d.Round(time.Millisecond).String()
 		},
@@ -146,6 +396,53 @@ func ExportHTML(report *TestReport, outputDir string) (string, error) {
 		"ttfbMs": func(r RequestResult) float64 {
 			return float64(r.TTFB.Microseconds()) / 1000.0
 		},
+		"ms":          durationMs,
+		"formatFloat": formatFloat,
+		"percentOf": func(v, total float64) float64 {
+			return v / total * 100
+		},
+		"kb": func(bytes float64) float64 {
+			return bytes / 1024
+		},
+		"float": func(n int64) float64 {
+			return float64(n)
+		},
+		"warmCount": func(s Summary) int {
+			return s.SuccessCount - s.NewConnCount
+		},
+		"join": strings.Join,
+		"protocolClass": func(proto string) string {
+			switch parseProtocol(proto) {
+			case HTTP3:
+				return "protocol-h3"
+			case HTTP2:
+				return "protocol-h2"
+			case HTTPPlain:
+				return "protocol-plain"
+			case H2C:
+				return "protocol-h2c"
+			default:
+				return "protocol-h1"
+			}
+		},
+		"secure": func(proto string) bool {
+			return parseProtocol(proto).Secure()
+		},
+		"ci": func(ci ConfidenceInterval) string {
+			return fmt.Sprintf("[%.0f, %.0f]", ci.Low, ci.High)
+		},
+		"percent": func(ratio float64) float64 {
+			return ratio * 100
+		},
+		"formatDate": func(t time.Time) string {
+			return t.Format("2006-01-02")
+		},
+		"shortHash": func(s string) string {
+			if len(s) > 16 {
+				return s[:16]
+			}
+			return s
+		},
 		// 根据 TTFB 值返回性能颜色类
 		"perfClass": func(ms float64) string {
 			if ms < 100 {
@@ -168,31 +465,22 @@ func ExportHTML(report *TestReport, outputDir string) (string, error) {
 			}
 			return "perf-poor"
 		},
-		// 生成安全的 HTML ID（替换特殊字符）
-		"safeID": func(s string) string {
-			re := regexp.MustCompile(`[^a-zA-Z0-9]+`)
-			return re.ReplaceAllString(s, "-")
-		},
-	}).Parse(htmlTemplate)
-	if err != nil {
-		return "", fmt.Errorf("解析 HTML 模板失败: %w", err)
+		// 单个节点每次请求的延迟折线图（内联 SVG）
+		"requestChart": requestChart,
 	}
+}
 
-	if err := tmpl.Execute(file, report); err != nil {
-		return "", fmt.Errorf("渲染 HTML 模板失败: %w", err)
+// newReportTemplate 解析 HTML 模板，附带共用的样式和模板函数
+func newReportTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs()).Parse(text)
+	if err != nil {
+		return nil, err
 	}
-
-	return filePath, nil
+	return tmpl.Parse(reportStyle)
 }
 
-// HTML 模板
-const htmlTemplate = `<!DOCTYPE html>
-<html lang="zh-CN">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>CDN 延迟测试报告 - {{formatTime .StartTime}}</title>
-    <script src="https://cdn.jsdelivr.net/npm/chart.js"></script>
+// 报告页和趋势页共用的样式
+const reportStyle = `{{define "style"}}
     <style>
         * { margin: 0; padding: 0; box-sizing: border-box; }
         body {
@@ -326,6 +614,14 @@ const htmlTemplate = `<!DOCTYPE html>
             background: rgba(245, 158, 11, 0.15);
             color: #fbbf24;
         }
+        .protocol-title.protocol-h2c {
+            background: rgba(139, 92, 246, 0.15);
+            color: #a78bfa;
+        }
+        .protocol-title.protocol-plain {
+            background: rgba(156, 163, 175, 0.15);
+            color: #d1d5db;
+        }
         .stacked-bar {
             display: flex;
             height: 32px;
@@ -400,6 +696,8 @@ const htmlTemplate = `<!DOCTYPE html>
         .protocol-h3 { background: rgba(16, 185, 129, 0.2); color: #34d399; }
         .protocol-h2 { background: rgba(59, 130, 246, 0.2); color: #60a5fa; }
         .protocol-h1 { background: rgba(245, 158, 11, 0.2); color: #fbbf24; }
+        .protocol-h2c { background: rgba(139, 92, 246, 0.2); color: #a78bfa; }
+        .protocol-plain { background: rgba(156, 163, 175, 0.2); color: #d1d5db; }
         
         /* 颜色等级 */
         .perf-excellent { color: #10b981; }
@@ -488,6 +786,18 @@ const htmlTemplate = `<!DOCTYPE html>
             padding: 0 20px 20px;
         }
         
+        .warning-list { list-style: none; }
+        .warning-list li {
+            padding: 10px 14px;
+            margin-bottom: 8px;
+            border-radius: 8px;
+            background: rgba(245, 158, 11, 0.1);
+            border-left: 3px solid #fbbf24;
+            color: #fbbf24;
+        }
+        .warning-text { color: #fbbf24; }
+        .mono { font-family: 'SF Mono', 'Monaco', 'Consolas', monospace; font-size: 0.85em; }
+
         .footer {
             text-align: center;
             padding: 20px;
@@ -500,11 +810,21 @@ const htmlTemplate = `<!DOCTYPE html>
         .node-gcp { border-left: 3px solid #60a5fa; }
         .node-default { border-left: 3px solid #a78bfa; }
     </style>
+{{end}}`
+
+// HTML 模板
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>CDN 延迟测试报告 - {{formatTime .StartTime}}</title>
+    {{template "style"}}
 </head>
 <body>
     <div class="container">
         <h1>🚀 CDN 延迟测试报告</h1>
-        <p class="subtitle">生成时间: {{formatTime .EndTime}} | 测试耗时: {{formatDuration .Duration}}</p>
+        <p class="subtitle">生成时间: {{formatTime .EndTime}} | 测试耗时: {{formatDuration .Duration}}{{if .Partial}} | <span class="warning-text">⚠️ 部分结果（{{if .Config.LoadRPS}}已压测 {{formatDuration .LoadElapsed}}/{{formatDuration .Config.LoadDuration}}{{else}}已完成 {{.CompletedRounds}}{{if .Config.TestCount}}/{{.Config.TestCount}}{{end}} 轮{{end}}）</span>{{end}}</p>
 
         <div class="card">
             <h2>📋 测试配置</h2>
@@ -517,29 +837,154 @@ const htmlTemplate = `<!DOCTYPE html>
                     <label>测试路径</label>
                     <span>{{.Config.Path}}</span>
                 </div>
+                {{if .Config.LoadRPS}}
+                <div class="config-item">
+                    <label>开环压测</label>
+                    <span>{{formatFloat .Config.LoadRPS}} rps × {{formatDuration .Config.LoadDuration}}</span>
+                </div>
+                {{else}}
+                {{if .Config.Duration}}
+                <div class="config-item">
+                    <label>测试时长</label>
+                    <span>{{formatDuration .Config.Duration}}（完成 {{.CompletedRounds}} 轮）</span>
+                </div>
+                {{end}}
+                {{if .Config.CIWidth}}
+                <div class="config-item">
+                    <label>自适应采样</label>
+                    <span>CI 宽度 ≤ {{.Config.CIWidth}}，{{.Config.AdaptiveMin}}–{{if .Config.TestCount}}{{.Config.TestCount}}{{else}}∞{{end}} 轮</span>
+                </div>
+                {{else if not .Config.Duration}}
                 <div class="config-item">
                     <label>每节点测试次数</label>
                     <span>{{.Config.TestCount}}</span>
                 </div>
+                {{end}}
+                {{end}}
                 <div class="config-item">
                     <label>测试节点数</label>
                     <span>{{len .Config.Endpoints}}</span>
                 </div>
+                <div class="config-item">
+                    <label>连接模式</label>
+                    <span>{{.Config.ConnectionMode}}</span>
+                </div>
+                {{if gt .Config.Concurrency 1}}
+                <div class="config-item">
+                    <label>并发</label>
+                    <span>{{.Config.Concurrency}} ({{.Config.Multiplex}})</span>
+                </div>
+                {{end}}
+                {{if .Config.Targets}}
+                <div class="config-item">
+                    <label>测试目标数</label>
+                    <span>{{len .Config.Targets}}</span>
+                </div>
+                {{end}}
             </div>
+            {{if .Config.Targets}}
+            <table>
+                <thead>
+                    <tr>
+                        <th>测试目标</th>
+                        <th>域名</th>
+                        <th>路径</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Config.Targets}}
+                    <tr>
+                        <td>{{.Name}}</td>
+                        <td class="mono">{{.Domain}}</td>
+                        <td class="mono">{{if .Path}}{{.Path}}{{else}}<span class="na">端点路径</span>{{end}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{end}}
+            <table>
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>IP</th>
+                        <th>协议</th>
+                        <th>请求地址</th>
+                        <th>SNI</th>
+                        <th>附加请求头</th>
+                        <th>超时</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Config.Endpoints}}
+                    <tr>
+                        <td>{{.Name}}</td>
+                        <td class="mono">{{.IP}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                        <td class="mono">{{.URL}}</td>
+                        <td>{{if .SNI}}{{.SNI}}{{else}}<span class="na">-</span>{{end}}</td>
+                        <td>{{if .Headers}}{{join .Headers ", "}}{{else}}<span class="na">-</span>{{end}}</td>
+                        <td>{{formatDuration .Timeout}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
         </div>
 
+        {{if .Warnings}}
+        <div class="card">
+            <h2>⚠️ 告警</h2>
+            <ul class="warning-list">
+                {{range .Warnings}}<li>{{.}}</li>{{end}}
+            </ul>
+        </div>
+        {{end}}
+
+        {{if .SLOVerdicts}}
+        <div class="card">
+            <h2>🎯 SLO 检查</h2>
+            {{$failed := .SLOFailures}}
+            <p class="chart-subtitle">{{if $failed}}<span class="error">❌ 未通过: {{$failed}}/{{len .SLOVerdicts}} 项</span>{{else}}<span class="success">✅ 全部通过: {{len .SLOVerdicts}} 项</span>{{end}}（端点 slo 覆盖全局 slo 中的同一指标）</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>SLO</th>
+                        <th>来源</th>
+                        <th>实际值</th>
+                        <th>结果</th>
+                        <th>说明</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .SLOVerdicts}}
+                    <tr>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                        <td class="mono">{{.SLO}}</td>
+                        <td>{{.Scope}}</td>
+                        <td>{{.Actual}}</td>
+                        <td>{{if eq .Result "fail"}}<span class="error">❌ 未通过</span>{{else if eq .Result "skipped"}}<span class="na">跳过</span>{{else}}<span class="success">✅ 通过</span>{{end}}</td>
+                        <td>{{if .Reason}}{{.Reason}}{{else}}<span class="na">-</span>{{end}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
         <div class="card">
             <h2>📊 性能对比图（按协议分组）</h2>
             <p class="chart-subtitle">堆叠图：CDN 延迟 + 服务端响应 = TTFB 总延迟（颜色表示性能档位）</p>
             {{range $proto := .Protocols}}
             <div class="protocol-section">
-                <h3 class="protocol-title {{if eq $proto "HTTP/3"}}protocol-h3{{else if eq $proto "HTTP/2"}}protocol-h2{{else}}protocol-h1{{end}}">{{$proto}}</h3>
+                <h3 class="protocol-title {{protocolClass $proto}}">{{$proto}}</h3>
                 <div class="chart-container">
                     {{range $s := index $.SummariesByProtocol $proto}}
                     <div class="chart-group">
                         <div class="chart-row">
                             <div class="chart-label">
-                                <span class="chart-name">{{$s.EndpointName}}</span>
+                                <span class="chart-name">{{$s.Label}}</span>
                             </div>
                             <div class="chart-bar-container">
                                 {{if $s.HasCDN}}
@@ -576,6 +1021,44 @@ const htmlTemplate = `<!DOCTYPE html>
             </div>
         </div>
 
+        {{if .Config.Targets}}
+        <div class="card">
+            <h2>🎯 按测试目标对比</h2>
+            {{range $t := .Config.Targets}}
+            <div class="protocol-section">
+                <h3 class="protocol-title">{{$t.Name}} <span class="mono">{{$t.Domain}}{{$t.Path}}</span></h3>
+                <table>
+                    <thead>
+                        <tr>
+                            <th>节点</th>
+                            <th>协议</th>
+                            <th>成功率</th>
+                            <th>TTFB 均值</th>
+                            <th>TTFB P50</th>
+                            <th>TTFB P95</th>
+                            <th>CDN P50</th>
+                            <th>服务端均值</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range index $.SummariesByTarget $t.Name}}
+                        <tr>
+                            <td>{{.EndpointName}}</td>
+                            <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                            <td class="success">{{.SuccessCount}}/{{.TotalTests}}</td>
+                            <td>{{printf "%.1f" .TTFBAvg}}</td>
+                            <td>{{printf "%.1f" .TTFBP50}}</td>
+                            <td>{{printf "%.1f" .TTFBP95}}</td>
+                            <td>{{if .HasCDN}}{{printf "%.1f" .CDNLatencyP50}}{{else}}<span class="na">-</span>{{end}}</td>
+                            <td>{{if .HasCDN}}{{printf "%.1f" .XResponseTimeAvg}}{{else}}<span class="na">-</span>{{end}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+            {{end}}
+        </div>
+        {{end}}
 
         <div class="card">
             <h2>📈 汇总统计</h2>
@@ -601,8 +1084,8 @@ const htmlTemplate = `<!DOCTYPE html>
                 <tbody>
                     {{range .Summaries}}
                     <tr>
-                        <td>{{.EndpointName}}</td>
-                        <td><span class="gauge-protocol {{if eq .Protocol "HTTP/3"}}protocol-h3{{else if eq .Protocol "HTTP/2"}}protocol-h2{{else}}protocol-h1{{end}}">{{.Protocol}}</span></td>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
                         <td class="success">{{.SuccessCount}}/{{.TotalTests}}</td>
                         <td class="{{if lt .TTFBAvg 100.0}}perf-excellent{{else if lt .TTFBAvg 300.0}}perf-good{{else if lt .TTFBAvg 500.0}}perf-fair{{else}}perf-poor{{end}}">{{printf "%.0f" .TTFBAvg}}</td>
                         <td class="{{if lt .TTFBP50 100.0}}perf-excellent{{else if lt .TTFBP50 300.0}}perf-good{{else if lt .TTFBP50 500.0}}perf-fair{{else}}perf-poor{{end}}">{{printf "%.0f" .TTFBP50}}</td>
@@ -621,6 +1104,419 @@ const htmlTemplate = `<!DOCTYPE html>
             </table>
         </div>
 
+        {{if .Config.LoadRPS}}
+        <div class="card">
+            <h2>🚦 开环压测速率</h2>
+            <p class="chart-subtitle">请求按固定时间表发送，不等待响应；TTFB 从计划发送时间算起（含发送滞后），不受 coordinated omission 影响（单位 ms）</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>目标 (rps)</th>
+                        <th>实际发送 (rps)</th>
+                        <th>达成率</th>
+                        <th>成功 (rps)</th>
+                        <th>发送滞后 P50</th>
+                        <th>发送滞后 P99</th>
+                        <th>发送滞后最大</th>
+                        <th>TTFB P50</th>
+                        <th>TTFB P99</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Summaries}}
+                    <tr>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                        <td>{{formatFloat .TargetRPS}}</td>
+                        <td>{{printf "%.2f" .AchievedRPS}}</td>
+                        <td>{{if .TargetRPS}}{{printf "%.1f%%" (percentOf .AchievedRPS .TargetRPS)}}{{else}}<span class="na">-</span>{{end}}</td>
+                        <td>{{printf "%.2f" .SuccessRPS}}</td>
+                        <td>{{printf "%.2f" .SendDelayP50}}</td>
+                        <td>{{printf "%.2f" .SendDelayP99}}</td>
+                        <td>{{printf "%.2f" .SendDelayMax}}</td>
+                        <td class="{{perfClass .TTFBP50}}">{{printf "%.0f" .TTFBP50}}</td>
+                        <td class="{{perfClass .TTFBP99}}">{{printf "%.0f" .TTFBP99}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if gt .Config.Concurrency 1}}
+        <div class="card">
+            <h2>🔀 并发测试</h2>
+            <p class="chart-subtitle">每轮每个节点同时发起 {{.Config.Concurrency}} 个请求（{{.Config.Multiplex}}）；单流 = 每个并发请求各自的 TTFB，极差 = 同一轮内最慢流与最快流的 TTFB 差值（单位 ms）</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>新建连接</th>
+                        <th>每轮连接</th>
+                        <th>单流 P50</th>
+                        <th>单流 P95</th>
+                        <th>最快流均值</th>
+                        <th>最慢流均值</th>
+                        <th>极差均值</th>
+                        <th>极差 P50</th>
+                        <th>极差 P95</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Summaries}}
+                    <tr>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                        <td>{{.ConnsOpened}}</td>
+                        <td>{{printf "%.1f" .ConnsPerBurst}}</td>
+                        <td class="{{perfClass .TTFBP50}}">{{printf "%.0f" .TTFBP50}}</td>
+                        <td class="{{perfClass .TTFBP95}}">{{printf "%.0f" .TTFBP95}}</td>
+                        <td class="{{perfClass .BurstFastestAvg}}">{{printf "%.0f" .BurstFastestAvg}}</td>
+                        <td class="{{perfClass .BurstSlowestAvg}}">{{printf "%.0f" .BurstSlowestAvg}}</td>
+                        <td>{{printf "%.1f" .BurstSpreadAvg}}</td>
+                        <td>{{printf "%.1f" .BurstSpreadP50}}</td>
+                        <td>{{printf "%.1f" .BurstSpreadP95}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        <div class="card">
+            <h2>📐 置信区间与显著性</h2>
+            <p class="chart-subtitle">TTFB 的 95% 置信区间由 bootstrap 重采样估计（单位 ms），区间越窄结果越稳定{{if .Config.CIWidth}}；自适应采样：P50 / P95 区间宽度都不超过 {{.Config.CIWidth}} 的节点提前停止{{end}}</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>样本</th>
+                        <th>均值</th>
+                        <th>均值 95% CI</th>
+                        <th>P50</th>
+                        <th>P50 95% CI</th>
+                        <th>P95</th>
+                        <th>P95 95% CI</th>
+                        {{if .Config.CIWidth}}
+                        <th>轮次</th>
+                        <th>收敛</th>
+                        {{end}}
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Summaries}}
+                    <tr>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                        <td>{{.SuccessCount}}</td>
+                        <td class="{{perfClass .TTFBAvg}}">{{printf "%.0f" .TTFBAvg}}</td>
+                        <td>{{ci .TTFBAvgCI}}</td>
+                        <td class="{{perfClass .TTFBP50}}">{{printf "%.0f" .TTFBP50}}</td>
+                        <td>{{ci .TTFBP50CI}}</td>
+                        <td class="{{perfClass .TTFBP95}}">{{printf "%.0f" .TTFBP95}}</td>
+                        <td>{{ci .TTFBP95CI}}</td>
+                        {{if $.Config.CIWidth}}
+                        <td>{{.Rounds}}</td>
+                        <td>{{if .Converged}}✅ 已收敛{{else}}<span class="warning-text">未收敛</span>{{end}}</td>
+                        {{end}}
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{if .Significance}}
+            <p class="chart-subtitle" style="margin-top: 20px;">Mann–Whitney U 秩和检验（同一测试目标下节点两两比较 TTFB，p 值经 Holm 多重比较校正，校正 p &lt; 0.05 视为差异显著）</p>
+            {{template "significance" .Significance}}
+            {{end}}
+            {{if .BaselineSignificance}}
+            <p class="chart-subtitle" style="margin-top: 20px;">与基线报告对比（Mann–Whitney U 检验，差值 = 当前 - 基线，p 值经 Holm 多重比较校正）</p>
+            {{template "significance" .BaselineSignificance}}
+            {{end}}
+        </div>
+
+        <div class="card">
+            <h2>⏱️ 连接阶段分解</h2>
+            <p class="chart-subtitle">TCP / TLS / QUIC 仅统计新建连接；等待 = 请求写完到收到首字节（单位 ms）</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>新建连接</th>
+                        <th>TCP 均值</th>
+                        <th>TCP P95</th>
+                        <th>TLS 均值</th>
+                        <th>TLS P95</th>
+                        <th>QUIC 均值</th>
+                        <th>QUIC P95</th>
+                        <th>发送均值</th>
+                        <th>等待均值</th>
+                        <th>等待 P50</th>
+                        <th>等待 P95</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Summaries}}
+                    <tr>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                        <td>{{.NewConnCount}}</td>
+                        <td>{{if gt .TCPConnectAvg 0.0}}{{printf "%.1f" .TCPConnectAvg}}{{else}}<span class="na">-</span>{{end}}</td>
+                        <td>{{if gt .TCPConnectP95 0.0}}{{printf "%.1f" .TCPConnectP95}}{{else}}<span class="na">-</span>{{end}}</td>
+                        <td>{{if gt .TLSHandshakeAvg 0.0}}{{printf "%.1f" .TLSHandshakeAvg}}{{else}}<span class="na">-</span>{{end}}</td>
+                        <td>{{if gt .TLSHandshakeP95 0.0}}{{printf "%.1f" .TLSHandshakeP95}}{{else}}<span class="na">-</span>{{end}}</td>
+                        <td>{{if gt .QUICHandshakeAvg 0.0}}{{printf "%.1f" .QUICHandshakeAvg}}{{else}}<span class="na">-</span>{{end}}</td>
+                        <td>{{if gt .QUICHandshakeP95 0.0}}{{printf "%.1f" .QUICHandshakeP95}}{{else}}<span class="na">-</span>{{end}}</td>
+                        <td>{{printf "%.2f" .RequestWriteAvg}}</td>
+                        <td class="{{perfClass .ServerWaitAvg}}">{{printf "%.1f" .ServerWaitAvg}}</td>
+                        <td class="{{perfClass .ServerWaitP50}}">{{printf "%.1f" .ServerWaitP50}}</td>
+                        <td class="{{perfClass .ServerWaitP95}}">{{printf "%.1f" .ServerWaitP95}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+
+        <div class="card">
+            <h2>🧊 冷/热连接对比</h2>
+            <p class="chart-subtitle">冷连接 = 新建 TCP/TLS 或 QUIC 连接（首次访问的真实体验），热连接 = 复用已有连接（TTFB，单位 ms）</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>冷连接数</th>
+                        <th>冷 均值</th>
+                        <th>冷 P50</th>
+                        <th>冷 P95</th>
+                        <th>冷 P99</th>
+                        <th>热连接数</th>
+                        <th>热 均值</th>
+                        <th>热 P50</th>
+                        <th>热 P95</th>
+                        <th>热 P99</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Summaries}}
+                    <tr>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                        <td>{{.NewConnCount}}</td>
+                        {{if gt .NewConnCount 0}}
+                        <td class="{{perfClass .ColdTTFBAvg}}">{{printf "%.0f" .ColdTTFBAvg}}</td>
+                        <td class="{{perfClass .ColdTTFBP50}}">{{printf "%.0f" .ColdTTFBP50}}</td>
+                        <td class="{{perfClass .ColdTTFBP95}}">{{printf "%.0f" .ColdTTFBP95}}</td>
+                        <td class="{{perfClass .ColdTTFBP99}}">{{printf "%.0f" .ColdTTFBP99}}</td>
+                        {{else}}
+                        <td colspan="4"><span class="na">-</span></td>
+                        {{end}}
+                        <td>{{warmCount .}}</td>
+                        {{if gt (warmCount .) 0}}
+                        <td class="{{perfClass .WarmTTFBAvg}}">{{printf "%.0f" .WarmTTFBAvg}}</td>
+                        <td class="{{perfClass .WarmTTFBP50}}">{{printf "%.0f" .WarmTTFBP50}}</td>
+                        <td class="{{perfClass .WarmTTFBP95}}">{{printf "%.0f" .WarmTTFBP95}}</td>
+                        <td class="{{perfClass .WarmTTFBP99}}">{{printf "%.0f" .WarmTTFBP99}}</td>
+                        {{else}}
+                        <td colspan="4"><span class="na">-</span></td>
+                        {{end}}
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+
+        <div class="card">
+            <h2>🗄️ 缓存命中统计</h2>
+            <p class="chart-subtitle">命中率 = HIT / 可识别缓存状态的请求数；HIT / MISS 列为对应请求的 TTFB（ms）</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>命中率</th>
+                        <th>HIT</th>
+                        <th>MISS</th>
+                        <th>STALE</th>
+                        <th>BYPASS</th>
+                        <th>未知</th>
+                        <th>HIT 均值</th>
+                        <th>HIT P50</th>
+                        <th>HIT P95</th>
+                        <th>MISS 均值</th>
+                        <th>MISS P50</th>
+                        <th>MISS P95</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Summaries}}
+                    <tr>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                        <td>{{if gt .SuccessCount .CacheUnknownCount}}{{printf "%.1f%%" (percent .CacheHitRatio)}}{{else}}<span class="na">-</span>{{end}}</td>
+                        <td class="success">{{.CacheHitCount}}</td>
+                        <td>{{.CacheMissCount}}</td>
+                        <td>{{.CacheStaleCount}}</td>
+                        <td>{{.CacheBypassCount}}</td>
+                        <td class="na">{{.CacheUnknownCount}}</td>
+                        {{if gt .CacheHitCount 0}}
+                        <td class="{{perfClass .HitTTFBAvg}}">{{printf "%.0f" .HitTTFBAvg}}</td>
+                        <td class="{{perfClass .HitTTFBP50}}">{{printf "%.0f" .HitTTFBP50}}</td>
+                        <td class="{{perfClass .HitTTFBP95}}">{{printf "%.0f" .HitTTFBP95}}</td>
+                        {{else}}
+                        <td colspan="3"><span class="na">-</span></td>
+                        {{end}}
+                        {{if gt .CacheMissCount 0}}
+                        <td class="{{perfClass .MissTTFBAvg}}">{{printf "%.0f" .MissTTFBAvg}}</td>
+                        <td class="{{perfClass .MissTTFBP50}}">{{printf "%.0f" .MissTTFBP50}}</td>
+                        <td class="{{perfClass .MissTTFBP95}}">{{printf "%.0f" .MissTTFBP95}}</td>
+                        {{else}}
+                        <td colspan="3"><span class="na">-</span></td>
+                        {{end}}
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+
+        <div class="card">
+            <h2>🔐 完整握手 vs 会话复用</h2>
+            <p class="chart-subtitle">会话复用: {{if .Config.SessionResume}}已启用{{else}}未启用{{end}} | 0-RTT: {{if .Config.EarlyData}}已启用{{else}}未启用{{end}}（仅统计新建连接，握手为 TLS 或 QUIC 握手，单位 ms）</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>完整握手次数</th>
+                        <th>完整握手 均值</th>
+                        <th>完整握手 P50</th>
+                        <th>完整握手 TTFB</th>
+                        <th>复用次数</th>
+                        <th>复用握手 均值</th>
+                        <th>复用握手 P50</th>
+                        <th>复用 TTFB</th>
+                        <th>0-RTT 次数</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Summaries}}
+                    <tr>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                        <td>{{.FullHandshakeCount}}</td>
+                        {{if gt .FullHandshakeCount 0}}
+                        <td>{{printf "%.1f" .FullHandshakeAvg}}</td>
+                        <td>{{printf "%.1f" .FullHandshakeP50}}</td>
+                        <td class="{{perfClass .FullTTFBAvg}}">{{printf "%.0f" .FullTTFBAvg}}</td>
+                        {{else}}
+                        <td colspan="3"><span class="na">-</span></td>
+                        {{end}}
+                        <td>{{.ResumedCount}}</td>
+                        {{if gt .ResumedCount 0}}
+                        <td>{{printf "%.1f" .ResumedHandshakeAvg}}</td>
+                        <td>{{printf "%.1f" .ResumedHandshakeP50}}</td>
+                        <td class="{{perfClass .ResumedTTFBAvg}}">{{printf "%.0f" .ResumedTTFBAvg}}</td>
+                        {{else}}
+                        <td colspan="3"><span class="na">-</span></td>
+                        {{end}}
+                        <td>{{.EarlyDataCount}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+
+        {{if .Config.DownloadBody}}
+        <div class="card">
+            <h2>📦 下载耗时与吞吐量</h2>
+            <p class="chart-subtitle">总耗时 = 发起请求到响应体读完；吞吐量 = 响应体大小 / 总耗时，P10 代表较慢的 10% 请求</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>平均大小 (KB)</th>
+                        <th>总耗时均值</th>
+                        <th>总耗时 P50</th>
+                        <th>总耗时 P95</th>
+                        <th>吞吐均值 (Mbps)</th>
+                        <th>吞吐 P10</th>
+                        <th>吞吐 P50</th>
+                        <th>吞吐 P90</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Summaries}}
+                    <tr>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Protocol}}">{{.Protocol}}</span></td>
+                        {{if .HasBody}}
+                        <td>{{printf "%.1f" (kb .BodyBytesAvg)}}</td>
+                        <td class="{{perfClass .TotalTimeAvg}}">{{printf "%.0f" .TotalTimeAvg}}</td>
+                        <td class="{{perfClass .TotalTimeP50}}">{{printf "%.0f" .TotalTimeP50}}</td>
+                        <td class="{{perfClass .TotalTimeP95}}">{{printf "%.0f" .TotalTimeP95}}</td>
+                        <td>{{printf "%.2f" .ThroughputAvg}}</td>
+                        <td>{{printf "%.2f" .ThroughputP10}}</td>
+                        <td>{{printf "%.2f" .ThroughputP50}}</td>
+                        <td>{{printf "%.2f" .ThroughputP90}}</td>
+                        {{else}}
+                        <td colspan="8"><span class="na">无响应体数据</span></td>
+                        {{end}}
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        <div class="card">
+            <h2>🔒 TLS 与证书信息</h2>
+            <p class="chart-subtitle">取每个端点首个新建连接的协商结果；证书剩余有效期少于 {{.Config.CertExpiryDays}} 天时告警</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>TLS 版本</th>
+                        <th>加密套件</th>
+                        <th>ALPN</th>
+                        <th>证书主体</th>
+                        <th>SAN</th>
+                        <th>签发者</th>
+                        <th>过期时间</th>
+                        <th>OCSP</th>
+                        <th>指纹</th>
+                        <th>证书校验</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .TLSRows}}
+                    <tr>
+                        <td>{{.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass .Endpoint.Protocol}}">{{.Endpoint.Protocol}}</span></td>
+                        {{with .TLS}}
+                        <td>{{.Version}}</td>
+                        <td>{{.CipherSuite}}</td>
+                        <td>{{.ALPN}}</td>
+                        <td>{{.Subject}}</td>
+                        <td>{{join .SANs ", "}}</td>
+                        <td>{{.Issuer}}</td>
+                        <td>{{formatDate .NotAfter}}</td>
+                        <td>{{if .OCSPStapled}}<span class="success">Yes</span>{{else}}<span class="na">No</span>{{end}}</td>
+                        <td class="mono" title="{{.Fingerprint}}">{{shortHash .Fingerprint}}</td>
+                        {{else}}
+                        <td colspan="9"><span class="na">{{if secure .Endpoint.Protocol}}无 TLS 信息{{else}}明文连接，无 TLS{{end}}</span></td>
+                        {{end}}
+                        <td{{if .Endpoint.InsecureSkipVerify}} class="warning-text"{{end}}>{{.Endpoint.Verification}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+
         {{range $name, $results := .Results}}
         <div class="collapsible" onclick="this.classList.toggle('open')">
             <div class="collapsible-header">
@@ -629,78 +1525,7 @@ const htmlTemplate = `<!DOCTYPE html>
             </div>
             <div class="collapsible-content">
                 <div class="collapsible-inner">
-                    <div class="chart-wrapper" style="height: 300px; margin-bottom: 20px;">
-                        <canvas id="chart-{{$name | safeID}}"></canvas>
-                    </div>
-                    <script>
-                    (function() {
-                        var ctx = document.getElementById('chart-{{$name | safeID}}').getContext('2d');
-                        var ttfbData = [{{range $i, $r := $results}}{{if $i}},{{end}}{{printf "%.2f" (ttfbMs $r)}}{{end}}];
-                        var cdnData = [{{range $i, $r := $results}}{{if $i}},{{end}}{{if gt $r.XResponseTime 0.0}}{{printf "%.2f" $r.CDNLatency}}{{else}}null{{end}}{{end}}];
-                        var serverData = [{{range $i, $r := $results}}{{if $i}},{{end}}{{if gt $r.XResponseTime 0.0}}{{printf "%.2f" $r.XResponseTime}}{{else}}null{{end}}{{end}}];
-                        var labels = [{{range $i, $r := $results}}{{if $i}},{{end}}{{$r.Index}}{{end}}];
-                        
-                        new Chart(ctx, {
-                            type: 'line',
-                            data: {
-                                labels: labels,
-                                datasets: [
-                                    {
-                                        label: 'TTFB (ms)',
-                                        data: ttfbData,
-                                        borderColor: 'rgba(0, 212, 255, 0.8)',
-                                        backgroundColor: 'rgba(0, 212, 255, 0.1)',
-                                        fill: false,
-                                        tension: 0.1,
-                                        pointRadius: 2,
-                                        pointHoverRadius: 5
-                                    },
-                                    {
-                                        label: 'CDN 延迟 (ms)',
-                                        data: cdnData,
-                                        borderColor: 'rgba(16, 185, 129, 0.8)',
-                                        backgroundColor: 'rgba(16, 185, 129, 0.1)',
-                                        fill: false,
-                                        tension: 0.1,
-                                        pointRadius: 2,
-                                        pointHoverRadius: 5
-                                    },
-                                    {
-                                        label: '服务端响应 (ms)',
-                                        data: serverData,
-                                        borderColor: 'rgba(139, 92, 246, 0.8)',
-                                        backgroundColor: 'rgba(139, 92, 246, 0.1)',
-                                        fill: false,
-                                        tension: 0.1,
-                                        pointRadius: 2,
-                                        pointHoverRadius: 5
-                                    }
-                                ]
-                            },
-                            options: {
-                                responsive: true,
-                                maintainAspectRatio: false,
-                                plugins: {
-                                    legend: {
-                                        labels: { color: '#e8e8e8' }
-                                    }
-                                },
-                                scales: {
-                                    x: {
-                                        title: { display: true, text: '请求序号', color: '#888' },
-                                        ticks: { color: '#888' },
-                                        grid: { color: 'rgba(255,255,255,0.05)' }
-                                    },
-                                    y: {
-                                        title: { display: true, text: '延迟 (ms)', color: '#888' },
-                                        ticks: { color: '#888' },
-                                        grid: { color: 'rgba(255,255,255,0.05)' }
-                                    }
-                                }
-                            }
-                        });
-                    })();
-                    </script>
+                    {{requestChart $results}}
                     <table>
                         <thead>
                             <tr>
@@ -708,22 +1533,44 @@ const htmlTemplate = `<!DOCTYPE html>
                                 <th>状态码</th>
                                 <th>协议</th>
                                 <th>连接</th>
+                                <th>缓存</th>
+                                <th>TCP (ms)</th>
+                                <th>握手 (ms)</th>
+                                <th>发送 (ms)</th>
+                                <th>等待 (ms)</th>
                                 <th>TTFB (ms)</th>
+                                {{if $.Config.DownloadBody}}
+                                <th>总耗时 (ms)</th>
+                                <th>大小 (KB)</th>
+                                <th>吞吐 (Mbps)</th>
+                                {{end}}
                                 <th>服务端响应 (ms)</th>
                                 <th>CDN 延迟 (ms)</th>
+                                <th>Server-Timing</th>
                                 <th>错误</th>
                             </tr>
                         </thead>
                         <tbody>
                             {{range $results}}
                             <tr>
-                                <td>{{.Index}}</td>
+                                <td>{{.Seq}}</td>
                                 <td>{{if eq .StatusCode 200}}<span class="success">{{.StatusCode}}</span>{{else if eq .StatusCode 0}}<span class="error">-</span>{{else}}{{.StatusCode}}{{end}}</td>
                                 <td>{{.ActualProto}}</td>
-                                <td>{{if .Reused}}<span class="reused">复用</span>{{else}}新建{{end}}</td>
+                                <td>{{if .Reused}}<span class="reused">复用</span>{{else if .EarlyData}}新建/0-RTT{{else if .TLSResumed}}新建/会话复用{{else}}新建{{end}}</td>
+                                <td>{{if eq .CacheStatus "HIT"}}<span class="success" title="{{.CacheHeader}}">HIT</span>{{else if .CacheStatus}}<span title="{{.CacheHeader}}">{{.CacheStatus}}</span>{{else}}<span class="na">-</span>{{end}}</td>
+                                <td>{{if gt .TCPConnect 0}}{{printf "%.2f" (ms .TCPConnect)}}{{else}}<span class="na">-</span>{{end}}</td>
+                                <td>{{if gt .QUICHandshake 0}}{{printf "%.2f" (ms .QUICHandshake)}}{{else if gt .TLSHandshake 0}}{{printf "%.2f" (ms .TLSHandshake)}}{{else}}<span class="na">-</span>{{end}}</td>
+                                <td>{{printf "%.2f" (ms .RequestWrite)}}</td>
+                                <td>{{printf "%.2f" (ms .ServerWait)}}</td>
                                 <td class="{{perfClass (ttfbMs .)}}">{{printf "%.2f" (ttfbMs .)}}</td>
+                                {{if $.Config.DownloadBody}}
+                                <td>{{printf "%.2f" (ms .TotalTime)}}</td>
+                                <td>{{printf "%.1f" (kb (float .BodyBytes))}}</td>
+                                <td>{{printf "%.2f" .Throughput}}</td>
+                                {{end}}
                                 <td>{{if gt .XResponseTime 0.0}}<span class="{{perfClass .XResponseTime}}">{{printf "%.2f" .XResponseTime}}</span>{{else}}<span class="na">-</span>{{end}}</td>
                                 <td>{{if gt .XResponseTime 0.0}}<span class="{{cdnPerfClass .CDNLatency}}">{{printf "%.2f" .CDNLatency}}</span>{{else}}<span class="na">-</span>{{end}}</td>
+                                <td class="mono">{{range $i, $m := .ServerTiming}}{{if $i}}, {{end}}{{$m.Name}}{{if $m.HasDuration}}={{printf "%.1f" $m.Duration}}{{end}}{{if $m.Description}} ({{$m.Description}}){{end}}{{else}}<span class="na">-</span>{{end}}</td>
                                 <td>{{if .Error}}<span class="error">{{.Error}}</span>{{else}}-{{end}}</td>
                             </tr>
                             {{end}}
@@ -735,9 +1582,45 @@ const htmlTemplate = `<!DOCTYPE html>
         {{end}}
 
         <div class="footer">
-            <p>💡 TTFB = Time To First Byte | CDN延迟 = TTFB - 服务端响应时间</p>
+            <p>💡 TTFB = Time To First Byte | CDN延迟 = TTFB - 服务端响应时间（来源: {{join .Config.TimingSources ", "}}）</p>
             <p>由 CDN Latency Tester 生成</p>
         </div>
     </div>
 </body>
-</html>`
+</html>
+{{define "significance"}}
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>A</th>
+                        <th>B</th>
+                        <th>A 中位数</th>
+                        <th>B 中位数</th>
+                        <th>差值 (B-A)</th>
+                        <th>U</th>
+                        <th>p 值</th>
+                        <th>校正 p 值</th>
+                        <th>结论</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .}}
+                    <tr>
+                        <td>{{.A}}</td>
+                        <td>{{.B}}</td>
+                        <td>{{printf "%.1f" .MedianA}}</td>
+                        <td>{{printf "%.1f" .MedianB}}</td>
+                        <td>{{printf "%+.1f" .Diff}}</td>
+                        {{if .Valid}}
+                        <td>{{printf "%.0f" .U}}</td>
+                        <td>{{printf "%.4f" .P}}</td>
+                        <td>{{printf "%.4f" .PAdjusted}}</td>
+                        <td>{{if .Significant}}<span class="warning-text">显著</span>{{else}}<span class="na">不显著</span>{{end}}</td>
+                        {{else}}
+                        <td colspan="4"><span class="na">样本不足（每组至少 5 个成功请求）</span></td>
+                        {{end}}
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+{{end}}`
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// ===============================
+// 本地模拟 CDN
+// ===============================
+
+// 在回环地址上同时提供 HTTP/1.1、HTTP/2、HTTP/3（共用一个 HTTPS 端口）以及明文 HTTP/1.1 和 h2c，
+// 证书由启动时生成的自签名 CA 签发。配合 tls.ca_file 可以离线端到端测试真实的客户端代码。
+
+// 默认证书域名
+const defaultFakeCDNDomain = "fake-cdn.test"
+
+// FakeCDNOptions 模拟 CDN 的默认行为
+// 单个请求可通过查询参数覆盖: delay、jitter、server_time、size、cache、status（强制返回该状态码）、reset（强制重置）
+type FakeCDNOptions struct {
+	Host        string        // 监听地址（默认 127.0.0.1）
+	Port        int           // HTTPS / HTTP/3 端口（0 表示随机分配）
+	Domain      string        // 证书域名（默认 fake-cdn.test）
+	Delay       time.Duration // 首字节前的固定延迟（模拟 CDN 自身耗时）
+	Jitter      time.Duration // 随机附加延迟上限
+	ServerTime  time.Duration // 模拟源站耗时，计入延迟并写入 x-source-response-time
+	ErrorRate   float64       // 返回错误状态码的比例（0-1）
+	ErrorStatus int           // 错误状态码（默认 503）
+	ResetRate   float64       // 不返回响应、直接重置连接的比例（0-1）
+	BodySize    int           // 响应体大小（字节）
+	CacheStatus string        // X-Cache 响应头，为空时不返回
+
+	RequireClientCert bool // HTTPS / HTTP/3 要求客户端证书（mTLS）
+}
+
+// fakeRequest 合并默认值和查询参数后单个请求的行为
+type fakeRequest struct {
+	delay      time.Duration
+	serverTime time.Duration
+	status     int
+	reset      bool
+	size       int
+	cache      string
+}
+
+// FakeCDN 本地模拟 CDN
+type FakeCDN struct {
+	opts          FakeCDNOptions
+	CACertPEM     []byte // 自签名 CA 证书（PEM）
+	ClientCertPEM []byte // 由该 CA 签发的客户端证书（PEM，mTLS 时使用）
+	ClientKeyPEM  []byte // 客户端私钥（PEM）
+	TLSAddr       string // HTTPS（TCP，HTTP/1.1 + HTTP/2）和 HTTP/3（UDP）共用的地址
+	PlainAddr     string // 明文 HTTP/1.1 和 h2c 地址
+
+	tlsServer   *http.Server
+	plainServer *http.Server
+	h3Server    *http3.Server
+	udpConn     net.PacketConn
+
+	requests atomic.Int64
+	failed   atomic.Int64
+	resets   atomic.Int64
+}
+
+// NewFakeCDN 生成证书并开始监听，返回时各协议已可接受连接
+func NewFakeCDN(opts FakeCDNOptions) (*FakeCDN, error) {
+	if opts.Host == "" {
+		opts.Host = "127.0.0.1"
+	}
+	if opts.Domain == "" {
+		opts.Domain = defaultFakeCDNDomain
+	}
+	if opts.ErrorStatus == 0 {
+		opts.ErrorStatus = http.StatusServiceUnavailable
+	}
+
+	certs, err := newFakeCertificates(opts.Domain, opts.Host)
+	if err != nil {
+		return nil, err
+	}
+	f := &FakeCDN{
+		opts:          opts,
+		CACertPEM:     certs.caPEM,
+		ClientCertPEM: certs.clientCertPEM,
+		ClientKeyPEM:  certs.clientKeyPEM,
+	}
+
+	tcpLn, udpConn, err := listenTCPAndUDP(opts.Host, opts.Port)
+	if err != nil {
+		return nil, err
+	}
+	plainLn, err := net.Listen("tcp", net.JoinHostPort(opts.Host, "0"))
+	if err != nil {
+		tcpLn.Close()
+		udpConn.Close()
+		return nil, fmt.Errorf("监听明文端口失败: %w", err)
+	}
+	f.TLSAddr = tcpLn.Addr().String()
+	f.PlainAddr = plainLn.Addr().String()
+	f.udpConn = udpConn
+
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{certs.server}}
+	if opts.RequireClientCert {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		tlsConfig.ClientCAs = certs.caPool
+	}
+
+	// HTTPS：ServeTLS 会自动启用 HTTP/2，客户端通过 ALPN 选择 h2 或 http/1.1
+	f.tlsServer = &http.Server{Handler: f, TLSConfig: tlsConfig.Clone(), ReadHeaderTimeout: 10 * time.Second}
+	go f.tlsServer.ServeTLS(tcpLn, "", "")
+
+	// 明文：同时接受 HTTP/1.1 和 h2c（prior knowledge）
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	f.plainServer = &http.Server{Handler: f, Protocols: &protocols, ReadHeaderTimeout: 10 * time.Second}
+	go f.plainServer.Serve(plainLn)
+
+	// HTTP/3：与 HTTPS 使用同一端口号
+	f.h3Server = &http3.Server{Handler: f, TLSConfig: http3.ConfigureTLSConfig(tlsConfig.Clone())}
+	go f.h3Server.Serve(udpConn)
+
+	return f, nil
+}
+
+// listenTCPAndUDP 在同一端口上监听 TCP 和 UDP；随机端口时若 UDP 端口被占用则重试
+func listenTCPAndUDP(host string, port int) (net.Listener, net.PacketConn, error) {
+	for attempt := 0; ; attempt++ {
+		tcpLn, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("监听 HTTPS 端口失败: %w", err)
+		}
+		addr := tcpLn.Addr().(*net.TCPAddr)
+		udpConn, err := net.ListenPacket("udp", net.JoinHostPort(host, strconv.Itoa(addr.Port)))
+		if err == nil {
+			return tcpLn, udpConn, nil
+		}
+		tcpLn.Close()
+		if port != 0 || attempt >= 10 {
+			return nil, nil, fmt.Errorf("监听 HTTP/3 端口失败: %w", err)
+		}
+	}
+}
+
+// Port HTTPS / HTTP/3 端口
+func (f *FakeCDN) Port() int {
+	_, port, _ := net.SplitHostPort(f.TLSAddr)
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// PlainPort 明文 HTTP/1.1 / h2c 端口
+func (f *FakeCDN) PlainPort() int {
+	_, port, _ := net.SplitHostPort(f.PlainAddr)
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// Stats 已处理的请求数、返回错误状态码的请求数、被重置的请求数
+func (f *FakeCDN) Stats() (requests, failed, resets int64) {
+	return f.requests.Load(), f.failed.Load(), f.resets.Load()
+}
+
+// Close 停止所有协议的服务
+func (f *FakeCDN) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err := errors.Join(
+		f.tlsServer.Shutdown(ctx),
+		f.plainServer.Shutdown(ctx),
+		f.h3Server.Close(),
+	)
+	// http3.Server 不会关闭外部传入的 UDP 连接
+	f.udpConn.Close()
+	return err
+}
+
+// ServeHTTP 按配置注入延迟、服务端耗时、错误状态码和连接重置
+func (f *FakeCDN) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests.Add(1)
+	req, err := f.parseRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.reset {
+		f.resets.Add(1)
+		resetConnection(w, r)
+		return
+	}
+
+	select {
+	case <-time.After(req.delay + req.serverTime):
+	case <-r.Context().Done():
+		return
+	}
+
+	if req.status >= 400 {
+		f.failed.Add(1)
+	}
+	h := w.Header()
+	h.Set("Content-Type", "text/plain; charset=utf-8")
+	h.Set("Content-Length", strconv.Itoa(req.size))
+	h.Set("x-source-response-time", strconv.FormatFloat(req.serverTime.Seconds(), 'f', -1, 64))
+	h.Set("Server-Timing", fmt.Sprintf("origin;dur=%s", strconv.FormatFloat(float64(req.serverTime.Microseconds())/1000, 'f', -1, 64)))
+	if req.cache != "" {
+		h.Set("X-Cache", req.cache)
+	}
+	w.WriteHeader(req.status)
+	if req.size > 0 {
+		w.Write([]byte(strings.Repeat("x", req.size)))
+	}
+}
+
+// parseRequest 合并默认行为和查询参数
+func (f *FakeCDN) parseRequest(r *http.Request) (fakeRequest, error) {
+	o := f.opts
+	req := fakeRequest{
+		delay:      o.Delay,
+		serverTime: o.ServerTime,
+		status:     http.StatusOK,
+		size:       o.BodySize,
+		cache:      o.CacheStatus,
+	}
+	jitter := o.Jitter
+	errorRate, resetRate := o.ErrorRate, o.ResetRate
+
+	q := r.URL.Query()
+	for _, d := range []struct {
+		name string
+		dst  *time.Duration
+	}{{"delay", &req.delay}, {"jitter", &jitter}, {"server_time", &req.serverTime}} {
+		if v := q.Get(d.name); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed < 0 {
+				return req, fmt.Errorf("无效的 %s: %q", d.name, v)
+			}
+			*d.dst = parsed
+		}
+	}
+	if v := q.Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return req, fmt.Errorf("无效的 size: %q", v)
+		}
+		req.size = n
+	}
+	if v := q.Get("status"); v != "" {
+		code, err := strconv.Atoi(v)
+		if err != nil || code < 200 || code > 599 {
+			return req, fmt.Errorf("无效的 status: %q", v)
+		}
+		req.status = code
+		errorRate = 0
+	}
+	if q.Has("reset") {
+		resetRate = 1
+	}
+	if q.Has("cache") {
+		req.cache = q.Get("cache")
+	}
+
+	if jitter > 0 {
+		req.delay += time.Duration(mrand.Int64N(int64(jitter)))
+	}
+	if errorRate > 0 && mrand.Float64() < errorRate {
+		req.status = o.ErrorStatus
+	}
+	req.reset = resetRate > 0 && mrand.Float64() < resetRate
+	return req, nil
+}
+
+// resetConnection 不返回响应直接中断：HTTP/1.x 以 RST 关闭 TCP 连接，HTTP/2 和 HTTP/3 重置当前流
+func resetConnection(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor == 1 {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				// TLS 连接直接关闭底层 TCP 连接，不发送 close_notify
+				if tlsConn, ok := conn.(*tls.Conn); ok {
+					conn = tlsConn.NetConn()
+				}
+				if tc, ok := conn.(*net.TCPConn); ok {
+					tc.SetLinger(0)
+				}
+				conn.Close()
+				return
+			}
+		}
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// fakeCerts 模拟 CDN 使用的证书
+type fakeCerts struct {
+	caPEM         []byte          // 自签名 CA 证书
+	caPool        *x509.CertPool  // 只包含该 CA 的证书池（校验客户端证书）
+	server        tls.Certificate // 服务端证书
+	clientCertPEM []byte          // 客户端证书（mTLS）
+	clientKeyPEM  []byte          // 客户端私钥
+}
+
+// newFakeCertificates 生成自签名 CA，并签发服务端证书（覆盖 domain、localhost 和监听 IP）和客户端证书
+func newFakeCertificates(domain, host string) (*fakeCerts, error) {
+	now := time.Now()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("生成 CA 密钥失败: %w", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CDN Latency Tester Fake CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("生成 CA 证书失败: %w", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, fmt.Errorf("解析 CA 证书失败: %w", err)
+	}
+
+	// issue 用 CA 签发证书，返回 DER 和私钥
+	issue := func(serial int64, tmpl *x509.Certificate) ([]byte, *ecdsa.PrivateKey, error) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("生成证书密钥失败: %w", err)
+		}
+		tmpl.SerialNumber = big.NewInt(serial)
+		tmpl.NotBefore = now.Add(-time.Hour)
+		tmpl.NotAfter = now.AddDate(1, 0, 0)
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("签发证书失败: %w", err)
+		}
+		return der, key, nil
+	}
+
+	serverTemplate := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: domain},
+		DNSNames:    []string{domain, "localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil && !ip.IsLoopback() && !ip.IsUnspecified() {
+		serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+	}
+	serverDER, serverKey, err := issue(2, serverTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDER, clientKey, err := issue(3, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "cdn-latency-tester"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, err
+	}
+	clientKeyDER, err := x509.MarshalPKCS8PrivateKey(clientKey)
+	if err != nil {
+		return nil, fmt.Errorf("编码客户端私钥失败: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return &fakeCerts{
+		caPEM:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		caPool:        pool,
+		server:        tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey},
+		clientCertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientDER}),
+		clientKeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: clientKeyDER}),
+	}, nil
+}
+
+// WriteFiles 在 dir 下写入 ca.pem（mTLS 时还有客户端证书）和指向本模拟 CDN 的 config.yaml，返回配置文件路径
+func (f *FakeCDN) WriteFiles(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建目录失败: %w", err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	caPath := filepath.Join(absDir, "ca.pem")
+	if err := os.WriteFile(caPath, f.CACertPEM, 0644); err != nil {
+		return "", fmt.Errorf("写入 CA 证书失败: %w", err)
+	}
+	clientCertPath := filepath.Join(absDir, "client.pem")
+	clientKeyPath := filepath.Join(absDir, "client-key.pem")
+	if f.opts.RequireClientCert {
+		if err := os.WriteFile(clientCertPath, f.ClientCertPEM, 0644); err != nil {
+			return "", fmt.Errorf("写入客户端证书失败: %w", err)
+		}
+		if err := os.WriteFile(clientKeyPath, f.ClientKeyPEM, 0600); err != nil {
+			return "", fmt.Errorf("写入客户端私钥失败: %w", err)
+		}
+	}
+
+	host, _, _ := net.SplitHostPort(f.TLSAddr)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 由 cdn-test fakecdn 生成，指向本地模拟 CDN（重启后端口和证书会变化）\n")
+	fmt.Fprintf(&b, "# 请求路径可带查询参数覆盖单个请求的行为，如 /?delay=50ms&server_time=10ms、/?status=503、/?reset=1\n")
+	fmt.Fprintf(&b, "domain: %q\npath: \"/\"\ntest_count: 20\ntimeout: \"5s\"\ninterval: \"50ms\"\n\n", f.opts.Domain)
+	fmt.Fprintf(&b, "tls:\n  ca_file: %q\n", caPath)
+	if f.opts.RequireClientCert {
+		fmt.Fprintf(&b, "  client_cert: %q\n  client_key: %q\n", clientCertPath, clientKeyPath)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "endpoints:\n")
+	for _, ep := range []struct {
+		name, proto string
+		port        int
+	}{
+		{"fake-h1", "http1", f.Port()},
+		{"fake-h2", "http2", f.Port()},
+		{"fake-h3", "http3", f.Port()},
+		{"fake-http", "http", f.PlainPort()},
+		{"fake-h2c", "h2c", f.PlainPort()},
+	} {
+		fmt.Fprintf(&b, "  - name: %q\n    ip: %q\n    protocol: %q\n    port: %d\n", ep.name, host, ep.proto, ep.port)
+	}
+	fmt.Fprintf(&b, "\noutput:\n  dir: %q\n  enable_log: false\n  enable_json: true\n  enable_html: true\n", filepath.Join(absDir, "output"))
+
+	configPath := filepath.Join(absDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	return configPath, nil
+}
+
+// runFakeCDN 启动模拟 CDN 并写入配套文件，直到 Ctrl-C / SIGTERM
+func runFakeCDN(opts FakeCDNOptions, dir string) int {
+	f, err := NewFakeCDN(opts)
+	if err != nil {
+		fmt.Printf("❌ 启动模拟 CDN 失败: %v\n", err)
+		return exitError
+	}
+	defer f.Close()
+
+	configPath, err := f.WriteFiles(dir)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitError
+	}
+
+	fmt.Println("🧪 本地模拟 CDN 已启动")
+	fmt.Println("==============================")
+	fmt.Printf("HTTPS / HTTP/2 / HTTP/3: %s\n", f.TLSAddr)
+	fmt.Printf("明文 HTTP/1.1 / h2c:     %s\n", f.PlainAddr)
+	fmt.Printf("证书域名: %s\n", f.opts.Domain)
+	fmt.Printf("默认行为: 延迟 %s + 抖动 <%s, 源站耗时 %s, 错误率 %g (%d), 重置率 %g\n",
+		opts.Delay, opts.Jitter, opts.ServerTime, opts.ErrorRate, f.opts.ErrorStatus, opts.ResetRate)
+	fmt.Printf("CA 证书:  %s\n", filepath.Join(filepath.Dir(configPath), "ca.pem"))
+	if opts.RequireClientCert {
+		fmt.Printf("客户端证书: %s（HTTPS / HTTP/3 要求 mTLS）\n", filepath.Join(filepath.Dir(configPath), "client.pem"))
+	}
+	fmt.Printf("测试配置: %s\n", configPath)
+	fmt.Printf("\n💡 另开终端运行: cdn-test run %s\n", configPath)
+	fmt.Println("   - 请求路径可带查询参数覆盖单个请求，如 /?delay=50ms&server_time=10ms、/?status=503、/?reset=1")
+	fmt.Println("   - 按 Ctrl-C 停止")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	requests, failed, resets := f.Stats()
+	fmt.Printf("\n⏹️ 已停止：共 %d 个请求，%d 个返回错误状态码，%d 个被重置\n", requests, failed, resets)
+	return exitOK
+}
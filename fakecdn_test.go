@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// runParallelRound / newEndpointClients 通过全局 logger 输出
+	logger, _ = NewLogger("", false)
+	os.Exit(m.Run())
+}
+
+// 各协议预期的实际协议版本
+var fakeCDNProtos = map[string]string{
+	"fake-h1":   "HTTP/1.1",
+	"fake-h2":   "HTTP/2.0",
+	"fake-h3":   "HTTP/3.0",
+	"fake-http": "HTTP/1.1",
+	"fake-h2c":  "HTTP/2.0",
+}
+
+// startFakeCDN 在进程内启动模拟 CDN，通过生成的 config.yaml（tls.ca_file 指向自签名 CA）加载配置
+func startFakeCDN(t *testing.T, opts FakeCDNOptions) (*FakeCDN, *Config) {
+	t.Helper()
+	f, err := NewFakeCDN(opts)
+	if err != nil {
+		t.Fatalf("启动模拟 CDN 失败: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	path, err := f.WriteFiles(t.TempDir())
+	if err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+	config, err := LoadConfig(path, ConfigOverrides{})
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if len(config.Endpoints) != len(fakeCDNProtos) {
+		t.Fatalf("节点数 = %d, 期望 %d", len(config.Endpoints), len(fakeCDNProtos))
+	}
+	return f, config
+}
+
+// measureAll 对每个节点请求一次 path（可带查询参数）
+func measureAll(t *testing.T, clients []EndpointClient, query string) map[string]RequestResult {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	results := make(map[string]RequestResult, len(clients))
+	for _, ec := range clients {
+		results[ec.Endpoint.Name] = measureRequest(ctx, ec.Client, ec.URL+query, ec.Target.Domain, ec.Options)
+	}
+	return results
+}
+
+func TestFakeCDNProtocols(t *testing.T) {
+	_, config := startFakeCDN(t, FakeCDNOptions{Delay: 20 * time.Millisecond, ServerTime: 5 * time.Millisecond})
+	for _, ep := range config.Endpoints {
+		if ep.Protocol.Secure() && (ep.Certs.CAFile == "" || ep.Certs.RootCAs == nil) {
+			t.Errorf("%s: 未通过 tls.ca_file 加载 CA", ep.Name)
+		}
+	}
+	clients := newEndpointClients(config)
+
+	for name, r := range measureAll(t, clients, "") {
+		if r.Error != "" {
+			t.Errorf("%s: 请求失败: %s", name, r.Error)
+			continue
+		}
+		if r.StatusCode != 200 {
+			t.Errorf("%s: 状态码 = %d, 期望 200", name, r.StatusCode)
+		}
+		if r.ActualProto != fakeCDNProtos[name] {
+			t.Errorf("%s: 实际协议 = %s, 期望 %s", name, r.ActualProto, fakeCDNProtos[name])
+		}
+		if r.TTFB < 25*time.Millisecond {
+			t.Errorf("%s: TTFB = %v, 期望不小于延迟 + 源站耗时 25ms", name, r.TTFB)
+		}
+		if r.ServerTimeSource != "x-source-response-time" || r.XResponseTime != 5 {
+			t.Errorf("%s: 服务端耗时 = %gms（来源 %q）, 期望 5ms（x-source-response-time）", name, r.XResponseTime, r.ServerTimeSource)
+		}
+		if want := durationMs(r.TTFB) - r.XResponseTime; r.CDNLatency < want-0.01 || r.CDNLatency > want+0.01 {
+			t.Errorf("%s: CDN 延迟 = %.2fms, 期望 TTFB - 服务端耗时 = %.2fms", name, r.CDNLatency, want)
+		}
+		if secure := name != "fake-http" && name != "fake-h2c"; secure != (r.TLSInfo != nil) {
+			t.Errorf("%s: TLSInfo = %v, 期望仅 TLS 协议记录", name, r.TLSInfo)
+		}
+	}
+}
+
+func TestFakeCDNQueryOverrides(t *testing.T) {
+	f, config := startFakeCDN(t, FakeCDNOptions{})
+	clients := newEndpointClients(config)
+
+	t.Run("延迟与源站耗时", func(t *testing.T) {
+		for name, r := range measureAll(t, clients, "?delay=40ms&server_time=15ms") {
+			if r.Error != "" {
+				t.Errorf("%s: 请求失败: %s", name, r.Error)
+				continue
+			}
+			if r.TTFB < 55*time.Millisecond {
+				t.Errorf("%s: TTFB = %v, 期望不小于 55ms", name, r.TTFB)
+			}
+			if r.XResponseTime != 15 {
+				t.Errorf("%s: 服务端耗时 = %gms, 期望 15ms", name, r.XResponseTime)
+			}
+		}
+	})
+
+	t.Run("错误状态码", func(t *testing.T) {
+		_, failedBefore, _ := f.Stats()
+		for name, r := range measureAll(t, clients, "?status=503") {
+			if r.Error != "" || r.StatusCode != 503 {
+				t.Errorf("%s: 状态码 = %d（错误 %q）, 期望 503", name, r.StatusCode, r.Error)
+			}
+		}
+		if _, failed, _ := f.Stats(); failed-failedBefore != int64(len(clients)) {
+			t.Errorf("模拟 CDN 记录的错误状态码请求数 = %d, 期望 %d", failed-failedBefore, len(clients))
+		}
+	})
+
+	t.Run("连接重置", func(t *testing.T) {
+		_, _, resetsBefore := f.Stats()
+		for name, r := range measureAll(t, clients, "?reset=1") {
+			if r.Error == "" {
+				t.Errorf("%s: 连接被重置时应返回错误，实际状态码 %d", name, r.StatusCode)
+			}
+		}
+		if _, _, resets := f.Stats(); resets-resetsBefore < int64(len(clients)) {
+			t.Errorf("模拟 CDN 记录的重置数 = %d, 期望至少 %d", resets-resetsBefore, len(clients))
+		}
+	})
+}
+
+func TestFakeCDNUntrustedCA(t *testing.T) {
+	_, config := startFakeCDN(t, FakeCDNOptions{})
+	// 去掉 tls.ca_file 后只信任系统证书，自签名证书应校验失败
+	for i := range config.Endpoints {
+		config.Endpoints[i].Certs = CertConfig{}
+	}
+	clients := newEndpointClients(config)
+
+	for name, r := range measureAll(t, clients, "") {
+		secure := name != "fake-http" && name != "fake-h2c"
+		switch {
+		case secure && !strings.Contains(r.Error, "certificate"):
+			t.Errorf("%s: 未信任 CA 时应返回证书错误，实际错误 %q", name, r.Error)
+		case !secure && r.Error != "":
+			t.Errorf("%s: 明文协议不校验证书，实际错误 %q", name, r.Error)
+		}
+	}
+}
+
+func TestFakeCDNParallelRounds(t *testing.T) {
+	f, config := startFakeCDN(t, FakeCDNOptions{Delay: 10 * time.Millisecond})
+	clients := newEndpointClients(config)
+	ctx := context.Background()
+
+	const rounds = 3
+	for round := 1; round <= rounds; round++ {
+		results := runParallelRound(ctx, newRoundTasks(clients, round, false, 1), round, rounds)
+		if len(results) != len(clients) {
+			t.Fatalf("第 %d 轮结果数 = %d, 期望 %d", round, len(results), len(clients))
+		}
+		for _, er := range results {
+			r := er.Result
+			if r.Error != "" {
+				t.Errorf("第 %d 轮 %s: 请求失败: %s", round, er.label(), r.Error)
+				continue
+			}
+			if r.Index != round {
+				t.Errorf("第 %d 轮 %s: 序号 = %d", round, er.label(), r.Index)
+			}
+			if r.ActualProto != fakeCDNProtos[er.Endpoint.Name] {
+				t.Errorf("第 %d 轮 %s: 实际协议 = %s, 期望 %s", round, er.label(), r.ActualProto, fakeCDNProtos[er.Endpoint.Name])
+			}
+			// warm 模式第二轮起复用首轮建立的连接（HTTP/3 复用同一 QUIC 连接）
+			if round > 1 && !r.Reused {
+				t.Errorf("第 %d 轮 %s: 期望复用连接", round, er.label())
+			}
+		}
+	}
+	if requests, _, _ := f.Stats(); requests != int64(rounds*len(clients)) {
+		t.Errorf("模拟 CDN 处理的请求数 = %d, 期望 %d", requests, rounds*len(clients))
+	}
+}
+
+func TestFakeCDNClientCertificate(t *testing.T) {
+	_, config := startFakeCDN(t, FakeCDNOptions{RequireClientCert: true})
+	clients := newEndpointClients(config)
+	for name, r := range measureAll(t, clients, "") {
+		if r.Error != "" {
+			t.Errorf("%s: 配置客户端证书后请求失败: %s", name, r.Error)
+		}
+	}
+
+	// 不带客户端证书时 HTTPS / HTTP/3 握手被拒绝，明文协议不受影响
+	for i := range config.Endpoints {
+		config.Endpoints[i].Certs.Certificate = nil
+	}
+	clients = newEndpointClients(config)
+	for name, r := range measureAll(t, clients, "") {
+		secure := name != "fake-http" && name != "fake-h2c"
+		if secure != (r.Error != "") {
+			t.Errorf("%s: 未带客户端证书时错误 = %q, 期望仅 TLS 协议失败", name, r.Error)
+		}
+	}
+}
+
+func TestFakeCDNConcurrentStreams(t *testing.T) {
+	const concurrency = 4
+	tests := []struct {
+		multiplex Multiplex
+		conns     int // 每个节点每轮期望新建的连接数
+	}{
+		// 并发请求共用一个连接（HTTP/1.1 在该连接上排队）
+		{SingleConn, 1},
+		// 每个并发流使用独立客户端，各建一个连接
+		{PerRequestConn, concurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.multiplex.String(), func(t *testing.T) {
+			_, config := startFakeCDN(t, FakeCDNOptions{Delay: 10 * time.Millisecond})
+			config.Concurrency = concurrency
+			config.Multiplex = tt.multiplex
+			clients := newEndpointClients(config)
+
+			for round := 1; round <= 2; round++ {
+				results := runParallelRound(context.Background(), newRoundTasks(clients, round, true, concurrency), round, 2)
+				if len(results) != len(clients)*concurrency {
+					t.Fatalf("第 %d 轮结果数 = %d, 期望 %d", round, len(results), len(clients)*concurrency)
+				}
+				for _, er := range results {
+					if er.Result.Error != "" {
+						t.Errorf("第 %d 轮 %s: 请求失败: %s", round, er.label(), er.Result.Error)
+					}
+					if er.Result.Stream < 1 || er.Result.Stream > concurrency {
+						t.Errorf("第 %d 轮 %s: 流序号 = %d", round, er.label(), er.Result.Stream)
+					}
+					// 冷连接每轮都重新建连
+					if er.Result.BurstConns != tt.conns {
+						t.Errorf("第 %d 轮 %s: 新建连接数 = %d, 期望 %d", round, er.label(), er.Result.BurstConns, tt.conns)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ===============================
+// 历史记录
+// ===============================
+
+// 默认历史记录文件（位于输出目录下）
+const defaultHistoryFile = "history.ndjson"
+
+// HistoryRun 历史记录中的一次测试运行，每行一条 JSON，只追加不修改
+type HistoryRun struct {
+	RunID           string                     `json:"run_id"` // 同报告文件名
+	StartTime       time.Time                  `json:"start_time"`
+	EndTime         time.Time                  `json:"end_time"`
+	Host            string                     `json:"host"` // 运行测试的主机名
+	Domain          string                     `json:"domain"`
+	ConnectionMode  string                     `json:"connection_mode"`
+	TestCount       int                        `json:"test_count"`
+	LoadRPS         float64                    `json:"load_rps,omitempty"` // 开环压测目标速率（按轮次测试时为 0）
+	CompletedRounds int                        `json:"completed_rounds"`
+	Partial         bool                       `json:"partial"`
+	Summaries       []Summary                  `json:"summaries"`
+	Results         map[string][]RequestResult `json:"results"`
+}
+
+// newHistoryRun 从测试报告生成历史记录
+func newHistoryRun(report *TestReport) HistoryRun {
+	host, _ := os.Hostname()
+	return HistoryRun{
+		RunID:           report.RunID(),
+		StartTime:       report.StartTime,
+		EndTime:         report.EndTime,
+		Host:            host,
+		Domain:          report.Config.Domain,
+		ConnectionMode:  report.Config.ConnectionMode,
+		TestCount:       report.Config.TestCount,
+		LoadRPS:         report.Config.LoadRPS,
+		CompletedRounds: report.CompletedRounds,
+		Partial:         report.Partial,
+		Summaries:       report.Summaries,
+		Results:         report.Results,
+	}
+}
+
+// AppendHistory 把本次测试追加到历史记录文件
+func AppendHistory(path string, report *TestReport) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建历史记录目录失败: %w", err)
+	}
+
+	data, err := json.Marshal(newHistoryRun(report))
+	if err != nil {
+		return fmt.Errorf("历史记录序列化失败: %w", err)
+	}
+	data = append(data, '\n')
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("打开历史记录失败: %w", err)
+	}
+	defer file.Close()
+
+	// 上次写入中断留下半行时先换行，避免本条记录与之拼在一起
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+
+	// 整行一次写入，避免并发运行时两条记录交错
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("写入历史记录失败: %w", err)
+	}
+	return nil
+}
+
+// LoadHistory 读取 since 之后开始的测试运行，返回记录和无法解析的行数（如写入中断留下的半行）
+func LoadHistory(path string, since time.Time) ([]HistoryRun, int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取历史记录失败: %w", err)
+	}
+	defer file.Close()
+
+	var runs []HistoryRun
+	skipped := 0
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var run HistoryRun
+			if jsonErr := json.Unmarshal(line, &run); jsonErr != nil {
+				skipped++
+			} else if !run.StartTime.Before(since) {
+				runs = append(runs, run)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("读取历史记录失败: %w", err)
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartTime.Before(runs[j].StartTime) })
+	return runs, skipped, nil
+}
+
+// ===============================
+// 趋势查询
+// ===============================
+
+// TrendPoint 单个节点某一天的汇总（合并当天所有运行的请求后计算百分位）
+type TrendPoint struct {
+	Date          string
+	Runs          int
+	Total         int
+	SuccessCount  int
+	SuccessRate   float64
+	TTFBP50       float64
+	TTFBP95       float64
+	HasCDN        bool
+	CDNLatencyP95 float64
+}
+
+// TrendSeries 单个节点（节点 × 协议 × 目标）的每日趋势
+type TrendSeries struct {
+	Key      string
+	Label    string
+	Protocol string
+	Color    string // 趋势图线条颜色
+	Points   []TrendPoint
+}
+
+// 趋势图线条颜色，按节点顺序循环使用
+var trendPalette = []string{"#00d4ff", "#10b981", "#a78bfa", "#f472b6", "#fbbf24", "#f87171", "#60a5fa", "#34d399"}
+
+// dailyTrend 按天汇总每个节点的趋势，filter 非空时只保留名称或键包含该字符串的节点
+func dailyTrend(runs []HistoryRun, filter string) []TrendSeries {
+	type dayValues struct {
+		runs    int
+		total   int
+		success int
+		ttfb    []float64
+		cdn     []float64
+		hasCDN  bool
+	}
+
+	var series []*TrendSeries
+	byKey := make(map[string]*TrendSeries)
+	days := make(map[string]map[string]*dayValues) // key -> date -> values
+
+	for _, run := range runs {
+		date := run.StartTime.Local().Format("2006-01-02")
+		for _, s := range run.Summaries {
+			key := s.Key()
+			if filter != "" && !strings.Contains(key, filter) {
+				continue
+			}
+			if _, ok := byKey[key]; !ok {
+				ts := &TrendSeries{
+					Key:      key,
+					Label:    s.Label(),
+					Protocol: s.Protocol,
+					Color:    trendPalette[len(series)%len(trendPalette)],
+				}
+				series = append(series, ts)
+				byKey[key] = ts
+				days[key] = make(map[string]*dayValues)
+			}
+
+			dv, ok := days[key][date]
+			if !ok {
+				dv = &dayValues{}
+				days[key][date] = dv
+			}
+			dv.runs++
+			for _, r := range run.Results[key] {
+				dv.total++
+				if r.Error != "" {
+					continue
+				}
+				dv.success++
+				dv.ttfb = append(dv.ttfb, durationMs(r.TTFB))
+				if r.XResponseTime > 0 {
+					dv.hasCDN = true
+					dv.cdn = append(dv.cdn, r.CDNLatency)
+				}
+			}
+		}
+	}
+
+	result := make([]TrendSeries, 0, len(series))
+	for _, ts := range series {
+		dates := make([]string, 0, len(days[ts.Key]))
+		for date := range days[ts.Key] {
+			dates = append(dates, date)
+		}
+		sort.Strings(dates)
+		for _, date := range dates {
+			dv := days[ts.Key][date]
+			ts.Points = append(ts.Points, TrendPoint{
+				Date:          date,
+				Runs:          dv.runs,
+				Total:         dv.total,
+				SuccessCount:  dv.success,
+				SuccessRate:   ratioPercent(dv.success, dv.total),
+				TTFBP50:       percentile(dv.ttfb, 0.50),
+				TTFBP95:       percentile(dv.ttfb, 0.95),
+				HasCDN:        dv.hasCDN,
+				CDNLatencyP95: percentile(dv.cdn, 0.95),
+			})
+		}
+		result = append(result, *ts)
+	}
+	return result
+}
+
+// trendDates 所有节点出现过的日期（升序）
+func trendDates(series []TrendSeries) []string {
+	seen := make(map[string]bool)
+	var dates []string
+	for _, s := range series {
+		for _, p := range s.Points {
+			if !seen[p.Date] {
+				seen[p.Date] = true
+				dates = append(dates, p.Date)
+			}
+		}
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+// Values 按日期对齐的指标值（缺失的日期为 NaN），metric 为 p50 / p95 / cdn_p95
+func (s TrendSeries) Values(dates []string, metric string) []float64 {
+	byDate := make(map[string]TrendPoint, len(s.Points))
+	for _, p := range s.Points {
+		byDate[p.Date] = p
+	}
+	values := make([]float64, len(dates))
+	for i, date := range dates {
+		p, ok := byDate[date]
+		switch {
+		case !ok || p.SuccessCount == 0:
+			values[i] = math.NaN()
+		case metric == "p50":
+			values[i] = p.TTFBP50
+		case metric == "cdn_p95" && p.HasCDN:
+			values[i] = p.CDNLatencyP95
+		case metric == "cdn_p95":
+			values[i] = math.NaN()
+		default:
+			values[i] = p.TTFBP95
+		}
+	}
+	return values
+}
+
+// 打印每日趋势表格
+func printTrendTable(series []TrendSeries, days int) {
+	fmt.Printf("\n📈 历史趋势（最近 %d 天，按天汇总）:\n", days)
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{"节点", "协议", "日期", "运行次数", "请求数", "成功率", "TTFB-P50", "TTFB-P95", "CDN-P95"}),
+	)
+
+	for _, s := range series {
+		for _, p := range s.Points {
+			p50, p95, cdn := "-", "-", "-"
+			if p.SuccessCount > 0 {
+				p50 = fmt.Sprintf("%.2f", p.TTFBP50)
+				p95 = fmt.Sprintf("%.2f", p.TTFBP95)
+			}
+			if p.HasCDN {
+				cdn = fmt.Sprintf("%.2f", p.CDNLatencyP95)
+			}
+			table.Append([]string{
+				s.Label,
+				s.Protocol,
+				p.Date,
+				fmt.Sprintf("%d", p.Runs),
+				fmt.Sprintf("%d", p.Total),
+				fmt.Sprintf("%.1f%%", p.SuccessRate),
+				p50,
+				p95,
+				cdn,
+			})
+		}
+	}
+
+	table.Render()
+	fmt.Println("\n💡 说明: 百分位由当天所有运行的成功请求合并计算（单位 ms），不是各次运行百分位的平均")
+}
+
+// ===============================
+// 趋势页
+// ===============================
+
+// TrendPage 趋势页数据
+type TrendPage struct {
+	Generated time.Time
+	Since     time.Time
+	Days      int
+	Runs      []HistoryRun
+	Series    []TrendSeries
+	Dates     []string
+	Charts    []trendChart
+}
+
+// Chart 趋势页中某个指标的折线图，每个节点一条线，缺失的日期直接连线
+func (p TrendPage) Chart(metric string) template.HTML {
+	series := make([]chartSeries, len(p.Series))
+	for i, s := range p.Series {
+		series[i] = chartSeries{
+			Label:  s.Label + " (" + s.Protocol + ")",
+			Color:  s.Color,
+			Values: s.Values(p.Dates, metric),
+		}
+	}
+	return lineChart{
+		Height:   360,
+		XLabels:  p.Dates,
+		YTitle:   "ms",
+		Unit:     "ms",
+		SpanGaps: true,
+		Series:   series,
+	}.HTML()
+}
+
+// ExportTrendHTML 生成历史趋势页
+func ExportTrendHTML(page TrendPage, outputDir string) (string, error) {
+	reportDir := filepath.Join(outputDir, "reports")
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return "", fmt.Errorf("创建报告目录失败: %w", err)
+	}
+	filePath := filepath.Join(reportDir, "trend_"+page.Generated.Format("2006-01-02_15-04-05")+".html")
+
+	tmpl, err := newReportTemplate("trend", trendTemplate)
+	if err != nil {
+		return "", fmt.Errorf("解析趋势页模板失败: %w", err)
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("创建 HTML 文件失败: %w", err)
+	}
+	defer file.Close()
+
+	if err := tmpl.Execute(file, page); err != nil {
+		return "", fmt.Errorf("渲染趋势页失败: %w", err)
+	}
+	return filePath, nil
+}
+
+// 趋势页模板
+const trendTemplate = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>CDN 延迟历史趋势 - {{formatDate .Generated}}</title>
+    {{template "style"}}
+</head>
+<body>
+    <div class="container">
+        <h1>📈 CDN 延迟历史趋势</h1>
+        <p class="subtitle">{{formatDate .Since}} 至 {{formatDate .Generated}}（最近 {{.Days}} 天）| 共 {{len .Runs}} 次运行 | 生成于 {{formatTime .Generated}}</p>
+
+        {{range $chart := .Charts}}
+        <div class="card">
+            <h2>{{$chart.Title}}</h2>
+            <p class="chart-subtitle">{{$chart.Subtitle}}</p>
+            {{$.Chart $chart.Metric}}
+        </div>
+        {{end}}
+
+        <div class="card">
+            <h2>📋 每日汇总</h2>
+            <p class="chart-subtitle">百分位由当天所有运行的成功请求合并计算（单位 ms）</p>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>节点</th>
+                        <th>协议</th>
+                        <th>日期</th>
+                        <th>运行次数</th>
+                        <th>请求数</th>
+                        <th>成功率</th>
+                        <th>TTFB P50</th>
+                        <th>TTFB P95</th>
+                        <th>CDN P95</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range $s := .Series}}{{range $s.Points}}
+                    <tr>
+                        <td>{{$s.Label}}</td>
+                        <td><span class="gauge-protocol {{protocolClass $s.Protocol}}">{{$s.Protocol}}</span></td>
+                        <td>{{.Date}}</td>
+                        <td>{{.Runs}}</td>
+                        <td>{{.Total}}</td>
+                        <td class="{{if ge .SuccessRate 99.0}}success{{else}}error{{end}}">{{printf "%.1f" .SuccessRate}}%</td>
+                        {{if gt .SuccessCount 0}}
+                        <td class="{{perfClass .TTFBP50}}">{{printf "%.1f" .TTFBP50}}</td>
+                        <td class="{{perfClass .TTFBP95}}">{{printf "%.1f" .TTFBP95}}</td>
+                        {{else}}
+                        <td colspan="2"><span class="na">-</span></td>
+                        {{end}}
+                        <td>{{if .HasCDN}}<span class="{{cdnPerfClass .CDNLatencyP95}}">{{printf "%.1f" .CDNLatencyP95}}</span>{{else}}<span class="na">-</span>{{end}}</td>
+                    </tr>
+                    {{end}}{{end}}
+                </tbody>
+            </table>
+        </div>
+
+        <div class="card">
+            <h2>🗂️ 运行记录</h2>
+            <table class="summary-table">
+                <thead>
+                    <tr>
+                        <th>运行</th>
+                        <th>开始时间</th>
+                        <th>主机</th>
+                        <th>域名</th>
+                        <th>连接模式</th>
+                        <th>轮次 / 速率</th>
+                        <th>节点数</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Runs}}
+                    <tr>
+                        <td class="mono">{{.RunID}}</td>
+                        <td>{{formatTime .StartTime}}</td>
+                        <td>{{.Host}}</td>
+                        <td>{{.Domain}}</td>
+                        <td>{{.ConnectionMode}}</td>
+                        <td>{{if .LoadRPS}}{{formatFloat .LoadRPS}} rps{{else}}{{.CompletedRounds}}{{if .TestCount}}/{{.TestCount}}{{end}}{{end}}{{if .Partial}} <span class="warning-text">（中断）</span>{{end}}</td>
+                        <td>{{len .Summaries}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+
+        <div class="footer">
+            <p>由 CDN Latency Tester 根据历史记录生成</p>
+        </div>
+    </div>
+</body>
+</html>`
+
+// trendChart 趋势页中的一张图
+type trendChart struct {
+	Metric   string
+	Title    string
+	Subtitle string
+}
+
+// trendCharts 趋势页展示的指标
+var trendCharts = []trendChart{
+	{Metric: "p95", Title: "⏱️ 每日 TTFB P95", Subtitle: "各节点每天所有成功请求的 TTFB P95（ms）"},
+	{Metric: "p50", Title: "⏱️ 每日 TTFB P50", Subtitle: "各节点每天所有成功请求的 TTFB 中位数（ms）"},
+	{Metric: "cdn_p95", Title: "🌐 每日 CDN 延迟 P95", Subtitle: "仅统计解析到服务端耗时的请求（ms）"},
+}
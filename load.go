@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ===============================
+// 开环压测（固定速率）
+// ===============================
+
+// 轮次模式每轮等所有请求返回后才发起下一轮（闭环），节点变慢时发送速率随之下降，
+// 慢请求期间本应发出的请求被"省略"（coordinated omission），延迟分布因此偏乐观。
+// 开环模式按计划时间表发送请求，与响应快慢无关，延迟从计划发送时间算起。
+
+// loadProgressInterval 压测进度输出间隔
+const loadProgressInterval = time.Second
+
+// loadRun 单个测试组合（endpoint × target）的压测结果
+type loadRun struct {
+	Results []RequestResult // 按计划发送顺序排列，不含被中断的请求
+	Sent    int             // 已发出的请求数（不含被中断的请求）
+	Window  time.Duration   // 发送窗口：首个请求的计划时间 -> 最后一个计入的请求实际发出 + 一个发送间隔
+}
+
+// loadCounters 所有测试组合共享的进度计数
+type loadCounters struct {
+	sent, done, failed atomic.Int64
+}
+
+// runLoad 对每个测试组合按固定速率发送请求，持续 duration，返回按报告键分组的结果
+// 被中断时停止调度，丢弃未完成的请求，elapsed 为实际压测时长
+func runLoad(ctx context.Context, clients []EndpointClient, rps float64, duration time.Duration) (runs map[string]loadRun, elapsed time.Duration) {
+	total := int(math.Round(rps * duration.Seconds()))
+	interval := time.Duration(float64(time.Second) / rps)
+
+	logger.Printf("\n🚦 开环压测: 每个节点 %s rps，持续 %s（%d 个组合，每个 %d 个请求）\n",
+		formatFloat(rps), duration, len(clients), total)
+
+	var counters loadCounters
+	var mu sync.Mutex
+	runs = make(map[string]loadRun, len(clients))
+
+	// 所有组合共用同一起点，各自独立调度
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, ec := range clients {
+		wg.Add(1)
+		go func(ec EndpointClient) {
+			defer wg.Done()
+			run := scheduleLoad(ctx, ec, start, interval, total, &counters)
+			mu.Lock()
+			runs[reportKey(ec.Endpoint, ec.Target)] = run
+			mu.Unlock()
+		}(ec)
+	}
+
+	// 定时输出进度，直到所有请求返回
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	ticker := time.NewTicker(loadProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-finished:
+			elapsed = duration
+			if ctx.Err() != nil {
+				elapsed = min(time.Since(start), duration)
+			}
+			return runs, elapsed
+		case <-ticker.C:
+			sent, done := counters.sent.Load(), counters.done.Load()
+			logger.Printf("  ⏱️ %s/%s 已发送 %d, 已完成 %d, 失败 %d, 进行中 %d\n",
+				min(time.Since(start), duration).Round(time.Second), duration,
+				sent, done, counters.failed.Load(), sent-done)
+		}
+	}
+}
+
+// scheduleLoad 按计划时间 start + i*interval 发送第 i 个请求，每个请求在独立 goroutine 中执行，
+// 不等待前一个请求返回；调度落后时立即补发，落后的时长计入该请求的延迟
+func scheduleLoad(ctx context.Context, ec EndpointClient, start time.Time, interval time.Duration, total int, counters *loadCounters) loadRun {
+	results := make([]RequestResult, total)
+	sentAt := make([]time.Time, total)
+	completed := make([]bool, total)
+	var wg sync.WaitGroup
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+schedule:
+	for i := 0; i < total; i++ {
+		intended := start.Add(time.Duration(i) * interval)
+		if wait := time.Until(intended); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				break schedule
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			break
+		}
+
+		sentAt[i] = time.Now()
+		counters.sent.Add(1)
+		wg.Add(1)
+		go func(i int, intended time.Time) {
+			defer wg.Done()
+			sendDelay := time.Since(intended)
+			result := measureRequest(ctx, ec.Client, ec.URL, ec.Target.Domain, ec.Options)
+			result.Index = i + 1
+			result.addSendDelay(sendDelay)
+			counters.done.Add(1)
+			if result.Error != "" {
+				counters.failed.Add(1)
+			}
+			// 被中断的请求只会得到取消错误，不计入结果
+			if ctx.Err() == nil {
+				results[i] = result
+				completed[i] = true
+			}
+		}(i, intended)
+	}
+	wg.Wait()
+
+	// 被中断时发送窗口截止到最后一个计入的请求，使实际速率与结果一致
+	var run loadRun
+	for i, ok := range completed {
+		if ok {
+			run.Results = append(run.Results, results[i])
+			run.Sent++
+			run.Window = sentAt[i].Sub(start) + interval
+		}
+	}
+	return run
+}
+
+// addSendDelay 记录发送滞后，并把它计入 TTFB / CDN 延迟 / 总耗时，使延迟从计划发送时间算起
+// 吞吐量仍按实际传输时间计算
+func (r *RequestResult) addSendDelay(d time.Duration) {
+	r.SendDelay = d
+	if r.TTFB > 0 {
+		r.TTFB += d
+		r.CDNLatency += durationMs(d)
+	}
+	if r.TotalTime > 0 {
+		r.TotalTime += d
+	}
+}
+
+// applyLoadStats 在汇总中补充目标速率、实际速率和发送滞后
+func applyLoadStats(s *Summary, run loadRun, rps float64) {
+	s.TargetRPS = rps
+	if run.Window > 0 {
+		s.AchievedRPS = float64(run.Sent) / run.Window.Seconds()
+		s.SuccessRPS = float64(s.SuccessCount) / run.Window.Seconds()
+	}
+	delays := make([]float64, len(run.Results))
+	for i, r := range run.Results {
+		delays[i] = durationMs(r.SendDelay)
+	}
+	s.SendDelayP50 = percentile(delays, 0.50)
+	s.SendDelayP99 = percentile(delays, 0.99)
+	s.SendDelayMax = percentile(delays, 1)
+}
+
+// printLoadTable 打印目标速率与实际速率对比
+func printLoadTable(summaries []Summary) {
+	fmt.Println("\n🚦 开环压测速率:")
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{
+			"节点", "协议", "目标(rps)", "实际发送(rps)", "达成率", "成功(rps)",
+			"发送滞后-P50", "发送滞后-P99", "发送滞后-最大", "TTFB-P50", "TTFB-P99",
+		}),
+	)
+
+	for _, s := range summaries {
+		ratio := "-"
+		if s.TargetRPS > 0 {
+			ratio = fmt.Sprintf("%.1f%%", s.AchievedRPS/s.TargetRPS*100)
+		}
+		table.Append([]string{
+			s.Label(),
+			s.Protocol,
+			formatFloat(s.TargetRPS),
+			fmt.Sprintf("%.2f", s.AchievedRPS),
+			ratio,
+			fmt.Sprintf("%.2f", s.SuccessRPS),
+			fmt.Sprintf("%.2f", s.SendDelayP50),
+			fmt.Sprintf("%.2f", s.SendDelayP99),
+			fmt.Sprintf("%.2f", s.SendDelayMax),
+			fmt.Sprintf("%.2f", s.TTFBP50),
+			fmt.Sprintf("%.2f", s.TTFBP99),
+		})
+	}
+
+	table.Render()
+	fmt.Println("\n💡 说明: 请求按固定时间表发送，不等待响应；TTFB 从计划发送时间算起（含发送滞后），不受 coordinated omission 影响；达成率明显低于 100% 说明本机调度跟不上目标速率")
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -117,12 +118,96 @@ func (l *Logger) LogConfig(cfg Config) {
 	l.Section("测试配置")
 	l.Printf("目标域名: %s\n", cfg.Domain)
 	l.Printf("测试路径: %s\n", cfg.Path)
-	l.Printf("每节点测试次数: %d\n", cfg.TestCount)
-	l.Printf("请求超时: %s\n", cfg.Timeout)
-	l.Printf("请求间隔: %s\n", cfg.Interval)
+	if cfg.LoadRPS > 0 {
+		l.Printf("开环压测: 每节点 %s rps，持续 %s\n", formatFloat(cfg.LoadRPS), cfg.LoadDuration)
+		l.Printf("请求超时: %s\n", cfg.Timeout)
+	} else {
+		switch {
+		case cfg.Adaptive.Enabled():
+			limit := "不限"
+			if cfg.Adaptive.MaxCount > 0 {
+				limit = fmt.Sprintf("%d", cfg.Adaptive.MaxCount)
+			}
+			l.Printf("自适应采样: P50/P95 的 95%% CI 宽度 ≤ %s，最少 %d 轮，最多 %s 轮\n", cfg.Adaptive, cfg.Adaptive.MinCount, limit)
+		case cfg.Duration == 0:
+			l.Printf("每节点测试次数: %d\n", cfg.TestCount)
+		}
+		if cfg.Duration > 0 {
+			l.Printf("测试时长: %s\n", cfg.Duration)
+		}
+		l.Printf("请求超时: %s\n", cfg.Timeout)
+		l.Printf("请求间隔: %s\n", cfg.Interval)
+	}
+	l.Printf("连接模式: %s\n", cfg.ConnectionMode)
+	if cfg.Concurrency > 1 {
+		l.Printf("并发: 每轮每节点 %d 个请求 (%s)\n", cfg.Concurrency, cfg.Multiplex)
+	}
+	l.Println("服务端耗时来源:")
+	for _, src := range cfg.TimingSources {
+		switch {
+		case isServerTimingHeader(src.Header) && src.Metric != "":
+			l.Printf("  - %s (指标 %s)\n", src.Header, src.Metric)
+		case src.Unit != "":
+			l.Printf("  - %s (单位 %s)\n", src.Header, src.Unit)
+		default:
+			l.Printf("  - %s\n", src.Header)
+		}
+	}
+	l.Printf("TLS 会话复用: %v, 0-RTT: %v\n", cfg.SessionResumption, cfg.EarlyData)
+	if certs := cfg.Certs.String(); certs != "" {
+		l.Printf("证书: %s\n", certs)
+	}
+	if cfg.DownloadBody {
+		if cfg.MaxBodyBytes > 0 {
+			l.Printf("下载响应体: 是（最多 %d 字节）\n", cfg.MaxBodyBytes)
+		} else {
+			l.Printf("下载响应体: 是\n")
+		}
+	}
+	if cfg.HasTargets() {
+		l.Println("测试目标:")
+		for _, t := range cfg.Targets {
+			path := t.Path
+			if path == "" {
+				path = "(端点路径)"
+			}
+			l.Printf("  - %s: %s%s\n", t.Name, t.Domain, path)
+		}
+	}
+	if len(cfg.SLOs) > 0 {
+		l.Println("SLO:")
+		for _, slo := range cfg.SLOs {
+			l.Printf("  - %s\n", slo.Expr)
+		}
+	}
 	l.Println("待测试节点:")
 	for _, ep := range cfg.Endpoints {
-		l.Printf("  - %s: %s (%s)\n", ep.Name, ep.IP, ep.Protocol)
+		l.Printf("  - %s: %s (%s) %s", ep.Name, ep.IP, ep.Protocol, ep.URL(cfg.BaseTarget()))
+		if ep.SNI != "" {
+			l.Printf(" [SNI: %s]", ep.SNI)
+		}
+		if ep.Timeout != cfg.Timeout {
+			l.Printf(" [超时: %s]", ep.Timeout)
+		}
+		if len(ep.Headers) > 0 {
+			l.Printf(" [附加请求头: %d 个]", len(ep.Headers))
+		}
+		if certs := ep.Certs.String(); ep.Protocol.Secure() && certs != cfg.Certs.String() {
+			if certs == "" {
+				certs = "使用系统证书校验"
+			}
+			l.Printf(" [%s]", certs)
+		}
+		var own []string
+		for _, slo := range ep.SLOs {
+			if !slo.Global {
+				own = append(own, slo.Expr)
+			}
+		}
+		if len(own) > 0 {
+			l.Printf(" [SLO: %s]", strings.Join(own, "; "))
+		}
+		l.Println()
 	}
 }
 
@@ -135,7 +220,7 @@ func (l *Logger) LogRequestResult(index, total int, result RequestResult) {
 		if result.Reused {
 			reusedStr = "复用"
 		}
-		l.Printf("  [%d/%d] ✓ TTFB: %.2fms, x-source-response-time: %.2fms, CDN延迟: %.2fms [%s] [实际协议: %s]\n",
+		l.Printf("  [%d/%d] ✓ TTFB: %.2fms, 服务端: %.2fms, CDN延迟: %.2fms [%s] [实际协议: %s]\n",
 			index, total,
 			float64(result.TTFB.Microseconds())/1000.0,
 			result.XResponseTime,
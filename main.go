@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -14,33 +18,67 @@ var logger *Logger
 // 单次请求任务
 type RequestTask struct {
 	Endpoint Endpoint
+	Target   Target
 	Client   *http.Client
 	URL      string
 	Domain   string
+	Options  RequestOptions
 	Index    int
+	Stream   int           // 并发流序号（1 ~ concurrency），concurrency 为 1 时为 0
+	Cold     bool          // 是否在请求前关闭空闲连接，强制新建连接
+	Dials    *atomic.Int64 // 所属测试组合的新建连接计数
 }
 
 // 请求结果（带端点信息）
 type EndpointResult struct {
 	Endpoint Endpoint
+	Target   Target
 	Result   RequestResult
 }
 
+// 日志中显示的标签，如 "节点A/HTTP/2" 或 "节点A/HTTP/2/首页"
+func (er EndpointResult) label() string {
+	label := er.Endpoint.Name + "/" + er.Endpoint.Protocol.String()
+	if er.Target.Name != "" {
+		label += "/" + er.Target.Name
+	}
+	if er.Result.Stream > 0 {
+		label += fmt.Sprintf("#%d", er.Result.Stream)
+	}
+	return label
+}
+
 // 并行执行单轮测试（所有节点同时发起请求）
-func runParallelRound(tasks []RequestTask, roundNum int, totalRounds int) []EndpointResult {
+func runParallelRound(ctx context.Context, tasks []RequestTask, roundNum int, totalRounds int) []EndpointResult {
 	var wg sync.WaitGroup
 	results := make([]EndpointResult, len(tasks))
 
-	logger.Printf("\n🔄 第 %d/%d 轮测试 (并发 %d 个请求)...\n", roundNum, totalRounds, len(tasks))
+	if totalRounds > 0 {
+		logger.Printf("\n🔄 第 %d/%d 轮测试 (并发 %d 个请求)...\n", roundNum, totalRounds, len(tasks))
+	} else {
+		// 守护模式不限轮次
+		logger.Printf("\n🔄 第 %d 轮测试 (并发 %d 个请求)...\n", roundNum, len(tasks))
+	}
+
+	// 冷连接在所有请求发出前统一关闭，避免同一连接上的并发流互相关闭；记录发起前的连接计数
+	dialsBefore := make(map[*atomic.Int64]int64)
+	for _, t := range tasks {
+		if t.Cold {
+			t.Client.CloseIdleConnections()
+		}
+		dialsBefore[t.Dials] = t.Dials.Load()
+	}
 
 	for i, task := range tasks {
 		wg.Add(1)
 		go func(idx int, t RequestTask) {
 			defer wg.Done()
-			result := measureRequest(t.Client, t.URL, t.Domain)
+			result := measureRequest(ctx, t.Client, t.URL, t.Domain, t.Options)
 			result.Index = t.Index
+			result.Stream = t.Stream
 			results[idx] = EndpointResult{
 				Endpoint: t.Endpoint,
+				Target:   t.Target,
 				Result:   result,
 			}
 		}(i, task)
@@ -48,54 +86,223 @@ func runParallelRound(tasks []RequestTask, roundNum int, totalRounds int) []Endp
 
 	wg.Wait()
 
+	// 并发时记录本轮每个测试组合实际新建的连接数
+	for i, t := range tasks {
+		if t.Stream > 0 {
+			results[i].Result.BurstConns = int(t.Dials.Load() - dialsBefore[t.Dials])
+		}
+	}
+
+	// 被中断的轮次结果不完整，不再打印
+	if ctx.Err() != nil {
+		return results
+	}
+
 	// 打印本轮结果
 	for _, er := range results {
 		if er.Result.Error != "" {
-			logger.Printf("  [%s/%s] ❌ 错误: %s\n",
-				er.Endpoint.Name, er.Endpoint.Protocol, er.Result.Error)
+			logger.Printf("  [%s] ❌ 错误: %s\n", er.label(), er.Result.Error)
 		} else {
 			reusedStr := "新"
 			if er.Result.Reused {
 				reusedStr = "复用"
+			} else if er.Result.EarlyData {
+				reusedStr = "新/0-RTT"
+			} else if er.Result.TLSResumed {
+				reusedStr = "新/会话复用"
 			}
-			logger.Printf("  [%s/%s] ✓ TTFB: %.2fms, 服务端: %.2fms, CDN延迟: %.2fms [%s] [%s]\n",
-				er.Endpoint.Name, er.Endpoint.Protocol,
+			logger.Printf("  [%s] ✓ TTFB: %.2fms, 服务端: %.2fms, CDN延迟: %.2fms [%s] [%s]",
+				er.label(),
 				float64(er.Result.TTFB.Microseconds())/1000.0,
 				er.Result.XResponseTime,
 				er.Result.CDNLatency,
 				reusedStr,
 				er.Result.ActualProto)
+			if er.Result.CacheStatus != CacheUnknown {
+				logger.Printf(" [%s]", er.Result.CacheStatus)
+			}
+			if er.Result.InsecureSkipVerify {
+				logger.Printf(" [⚠️ 未校验证书]")
+			}
+			if er.Result.TotalTime > 0 {
+				logger.Printf(" 下载: %d 字节, 总耗时: %.2fms, %.2f Mbps",
+					er.Result.BodyBytes,
+					durationMs(er.Result.TotalTime),
+					er.Result.Throughput)
+			}
+			logger.Println()
 		}
 	}
 
 	return results
 }
 
+// 测试矩阵中的一个组合（endpoint × target）及其客户端
+type EndpointClient struct {
+	Endpoint Endpoint
+	Target   Target
+	Client   *http.Client
+	Streams  []*http.Client // 并发流使用的客户端（per_request_conn 时每个流一个独立客户端，否则只有 Client）
+	Dials    *atomic.Int64  // 新建连接计数（所有流合计）
+	URL      string
+	Options  RequestOptions
+}
+
+// 按协议创建客户端，不支持的协议返回 nil
+func newProtocolClient(endpoint Endpoint, opts ClientOptions) *http.Client {
+	switch endpoint.Protocol {
+	case HTTP1:
+		return createHTTP1Client(endpoint.IP, endpoint.Timeout, opts)
+	case HTTP2:
+		return createHTTP2Client(endpoint.IP, endpoint.Timeout, opts)
+	case HTTP3:
+		return createHTTP3Client(endpoint.IP, endpoint.Timeout, opts)
+	case HTTPPlain:
+		return createHTTPClient(endpoint.IP, endpoint.Timeout, opts)
+	case H2C:
+		return createH2CClient(endpoint.IP, endpoint.Timeout, opts)
+	default:
+		return nil
+	}
+}
+
+// 展开测试矩阵：endpoint × target，每个组合使用独立客户端，避免不同目标共享连接
+func newEndpointClients(config *Config) []EndpointClient {
+	clients := make([]EndpointClient, 0, len(config.Endpoints)*len(config.Targets))
+
+	// per_request_conn 时每个并发流使用独立客户端（独立连接池）
+	streams := 1
+	if config.Multiplex == PerRequestConn {
+		streams = config.Concurrency
+	}
+
+	for _, endpoint := range config.Endpoints {
+		for _, target := range config.Targets {
+			dials := new(atomic.Int64)
+			clientOptions := newClientOptions(*config, endpoint)
+			clientOptions.OnDial = func() { dials.Add(1) }
+
+			ec := EndpointClient{
+				Endpoint: endpoint,
+				Target:   target,
+				Dials:    dials,
+				URL:      endpoint.URL(target),
+				Options:  newRequestOptions(*config, endpoint),
+			}
+			for i := 0; i < streams; i++ {
+				client := newProtocolClient(endpoint, clientOptions)
+				if client == nil {
+					break
+				}
+				ec.Streams = append(ec.Streams, client)
+			}
+			if len(ec.Streams) == 0 {
+				logger.Error("不支持的协议: %v", endpoint.Protocol)
+				continue
+			}
+			ec.Client = ec.Streams[0]
+			clients = append(clients, ec)
+		}
+	}
+
+	return clients
+}
+
+// 构建一轮的请求任务，每个组合同时发起 concurrency 个请求
+func newRoundTasks(clients []EndpointClient, round int, cold bool, concurrency int) []RequestTask {
+	tasks := make([]RequestTask, 0, len(clients)*concurrency)
+	for _, ec := range clients {
+		for s := 0; s < concurrency; s++ {
+			task := RequestTask{
+				Endpoint: ec.Endpoint,
+				Target:   ec.Target,
+				Client:   ec.Streams[s%len(ec.Streams)],
+				URL:      ec.URL,
+				Domain:   ec.Target.Domain,
+				Options:  ec.Options,
+				Index:    round,
+				Cold:     cold,
+				Dials:    ec.Dials,
+			}
+			if concurrency > 1 {
+				task.Stream = s + 1
+			}
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// 按轮次测试：每轮所有节点同时发起请求，结果追加到 endpointResults，返回已完成的轮次
+// 配置 duration 时持续到达到时长；自适应采样时已收敛的节点不再参与后续轮次
+func runRounds(ctx context.Context, clients []EndpointClient, config *Config, endpointResults map[string][]RequestResult) int {
+	limit := config.roundLimit()
+	var deadline time.Time
+	if config.Duration > 0 {
+		deadline = time.Now().Add(config.Duration)
+	}
+	expired := func() bool {
+		return !deadline.IsZero() && !time.Now().Before(deadline)
+	}
+
+	active := clients
+	completedRounds := 0
+	for round := 1; limit == 0 || round <= limit; round++ {
+		// 构建本轮任务
+		tasks := newRoundTasks(active, round, config.ConnectionMode.coldRound(round), config.Concurrency)
+
+		// 并行执行
+		results := runParallelRound(ctx, tasks, round, limit)
+		if ctx.Err() != nil {
+			// 丢弃被中断的轮次，避免取消错误污染统计
+			break
+		}
+
+		// 收集结果
+		for _, er := range results {
+			key := reportKey(er.Endpoint, er.Target)
+			endpointResults[key] = append(endpointResults[key], er.Result)
+		}
+
+		completedRounds = round
+
+		// 自适应采样：剔除已收敛的节点，全部收敛时提前结束
+		if config.Adaptive.Enabled() {
+			active = config.Adaptive.pending(active, endpointResults, round)
+		}
+		if len(active) == 0 || round == limit || expired() {
+			break
+		}
+
+		// 轮次间隔
+		select {
+		case <-ctx.Done():
+		case <-time.After(config.Interval):
+		}
+		if ctx.Err() != nil || expired() {
+			break
+		}
+	}
+	return completedRounds
+}
+
 // ===============================
 // 主函数
 // ===============================
 
 func main() {
-	var err error
-
-	// 加载配置文件
-	configPath := "config.yaml"
-	if len(os.Args) > 1 {
-		configPath = os.Args[1]
-	}
+	os.Exit(runCLI(os.Args[1:]))
+}
 
-	config, err := LoadConfig(configPath)
-	if err != nil {
-		fmt.Printf("❌ 加载配置失败: %v\n", err)
-		fmt.Println("请确保 config.yaml 文件存在，或指定配置文件路径: ./cdn-test [config.yaml]")
-		return
-	}
+// 执行测试并生成报告，返回进程退出码
+func runTest(config *Config) int {
+	var err error
 
 	// 初始化日志记录器
 	logger, err = NewLogger(config.OutputDir, config.EnableLog)
 	if err != nil {
 		fmt.Printf("❌ 初始化日志失败: %v\n", err)
-		return
+		return exitError
 	}
 	defer logger.Close()
 
@@ -106,92 +313,123 @@ func main() {
 	logger.Println("==============================")
 	logger.LogConfig(*config)
 
-	url := fmt.Sprintf("https://%s%s", config.Domain, config.Path)
-
-	// 为每个 endpoint 创建客户端
-	type EndpointClient struct {
-		Endpoint Endpoint
-		Client   *http.Client
-	}
-	clients := make([]EndpointClient, 0, len(config.Endpoints))
+	clients := newEndpointClients(config)
 
-	for _, endpoint := range config.Endpoints {
-		var client *http.Client
-		switch endpoint.Protocol {
-		case HTTP1:
-			client = createHTTP1Client(endpoint.IP, config.Timeout)
-		case HTTP2:
-			client = createHTTP2Client(endpoint.IP, config.Timeout)
-		case HTTP3:
-			client = createHTTP3Client(endpoint.IP, config.Timeout)
-		default:
-			logger.Error("不支持的协议: %v", endpoint.Protocol)
-			continue
-		}
-		clients = append(clients, EndpointClient{Endpoint: endpoint, Client: client})
-	}
-
-	// 收集每个 endpoint 的所有结果
+	// 收集每个 endpoint × target 的所有结果
 	endpointResults := make(map[string][]RequestResult)
 	for _, ec := range clients {
-		endpointResults[ec.Endpoint.Name+"|"+ec.Endpoint.Protocol.String()] = make([]RequestResult, 0, config.TestCount)
+		endpointResults[reportKey(ec.Endpoint, ec.Target)] = []RequestResult{}
 	}
 
-	// 并行测试：每轮所有节点同时发起请求
-	for round := 1; round <= config.TestCount; round++ {
-		// 构建本轮任务
-		tasks := make([]RequestTask, len(clients))
-		for i, ec := range clients {
-			tasks[i] = RequestTask{
-				Endpoint: ec.Endpoint,
-				Client:   ec.Client,
-				URL:      url,
-				Domain:   config.Domain,
-				Index:    round,
-			}
+	// Ctrl-C / SIGTERM 时取消进行中的请求并停止调度，已完成的轮次照常生成报告
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var loadRuns map[string]loadRun
+	if config.LoadRPS > 0 {
+		// 开环压测：按固定速率发送，代替按轮次测试
+		var elapsed time.Duration
+		loadRuns, elapsed = runLoad(ctx, clients, config.LoadRPS, config.LoadDuration)
+		interrupted := ctx.Err() != nil
+		for key, run := range loadRuns {
+			endpointResults[key] = run.Results
 		}
 
-		// 并行执行
-		results := runParallelRound(tasks, round, config.TestCount)
-
-		// 收集结果
-		for _, er := range results {
-			key := er.Endpoint.Name + "|" + er.Endpoint.Protocol.String()
-			endpointResults[key] = append(endpointResults[key], er.Result)
+		// 恢复默认信号处理，再次 Ctrl-C 可直接退出
+		stop()
+		report.SetLoadElapsed(elapsed, interrupted)
+		if report.Partial {
+			logger.Printf("\n⚠️ 测试被中断，已压测 %s/%s，基于已返回的请求生成报告\n", elapsed.Round(time.Second), config.LoadDuration)
 		}
-
-		// 轮次间隔
-		if round < config.TestCount {
-			time.Sleep(config.Interval)
+	} else {
+		completedRounds := runRounds(ctx, clients, config, endpointResults)
+		interrupted := ctx.Err() != nil
+
+		// 恢复默认信号处理，再次 Ctrl-C 可直接退出
+		stop()
+		report.SetCompletedRounds(completedRounds, interrupted)
+		if report.Partial {
+			logger.Printf("\n⚠️ 测试被中断，已完成 %s 轮，基于已完成的轮次生成报告\n", report.roundsText())
 		}
 	}
 
 	// 整理结果并生成汇总
 	var allSummaries []Summary
+	var sloVerdicts []SLOVerdict
 
 	for _, ec := range clients {
-		key := ec.Endpoint.Name + "|" + ec.Endpoint.Protocol.String()
+		key := reportKey(ec.Endpoint, ec.Target)
 		results := endpointResults[key]
 
-		// 保存结果到报告 (使用带协议的名称)
-		reportKey := fmt.Sprintf("%s (%s)", ec.Endpoint.Name, ec.Endpoint.Protocol)
-		report.AddResults(reportKey, results)
+		// 保存结果到报告 (使用带协议和目标的名称)
+		report.AddResults(key, results)
+		report.SetTLSInfo(ec.Endpoint, ec.Target, results)
 
-		// 打印详细结果
-		printDetailTable(ec.Endpoint, results)
+		// 打印详细结果（开环压测请求数较多，逐条结果见导出的原始样本）
+		if loadRuns == nil {
+			printDetailTable(ec.Endpoint, ec.Target, results)
+		}
 
 		// 计算并保存汇总
-		summary := calculateSummary(ec.Endpoint, results)
+		summary := calculateSummary(ec.Endpoint, ec.Target, results)
+		if loadRuns != nil {
+			applyLoadStats(&summary, loadRuns[key], config.LoadRPS)
+		}
+		if config.Concurrency > 1 {
+			applyBurstStats(&summary, results)
+		}
+		if config.Adaptive.Enabled() {
+			applyAdaptiveStats(&summary, results, config.Adaptive)
+		}
 		allSummaries = append(allSummaries, summary)
+		sloVerdicts = append(sloVerdicts, evaluateSLOs(summary, results, ec.Endpoint.SLOs)...)
 	}
 
 	// 打印汇总对比
 	if len(allSummaries) > 0 {
 		printSummaryTable(allSummaries)
+		if loadRuns != nil {
+			printLoadTable(allSummaries)
+		}
+		if config.Concurrency > 1 {
+			printBurstTable(allSummaries, config.Multiplex)
+		}
+		printPhaseTable(allSummaries)
+		printConnectionTable(allSummaries)
+		printCacheTable(allSummaries)
+		if config.DownloadBody {
+			printTransferTable(allSummaries)
+		}
+		printConfidenceTable(allSummaries)
+		if config.Adaptive.Enabled() {
+			printAdaptiveTable(allSummaries, config.Adaptive)
+		}
 	}
 
 	// 完成报告
 	report.Finalize(allSummaries)
+	printSignificanceTable("🔬 节点差异显著性检验", report.Significance)
+
+	// 打印证书信息和告警
+	printTLSTable(report.TLSRows())
+	printWarnings(report.Warnings)
+
+	// SLO 检查
+	report.SLOVerdicts = sloVerdicts
+	sloFailed := 0
+	if len(sloVerdicts) > 0 {
+		sloFailed = printSLOTable(sloVerdicts)
+	}
+
+	// 加载基线报告（中断的部分结果不做对比），显著性检验结果随报告一起导出
+	var baseline *TestReport
+	var baselineErr error
+	if config.Baseline != "" && !report.Partial {
+		baseline, baselineErr = LoadReport(config.Baseline)
+		if baselineErr == nil {
+			report.BaselineSignificance = baselineSignificance(baseline, report)
+		}
+	}
 
 	// 导出报告
 	logger.Section("报告生成")
@@ -214,9 +452,56 @@ func main() {
 		}
 	}
 
+	if config.EnableCSV {
+		csvPath, err := ExportCSV(report, config.OutputDir)
+		if err != nil {
+			logger.Error("导出 CSV 失败: %v", err)
+		} else {
+			logger.Printf("📊 CSV 样本: %s\n", csvPath)
+		}
+	}
+
+	if config.EnableNDJSON {
+		ndjsonPath, err := ExportNDJSON(report, config.OutputDir)
+		if err != nil {
+			logger.Error("导出 NDJSON 失败: %v", err)
+		} else {
+			logger.Printf("📊 NDJSON 样本: %s\n", ndjsonPath)
+		}
+	}
+
+	if config.HistoryEnabled {
+		if err := AppendHistory(config.HistoryPath, report); err != nil {
+			logger.Error("写入历史记录失败: %v", err)
+		} else {
+			logger.Printf("🗃️ 历史记录: %s\n", config.HistoryPath)
+		}
+	}
+
 	if logger.GetLogPath() != "" {
 		logger.Printf("📝 日志文件: %s\n", logger.GetLogPath())
 	}
 
+	// 与基线对比做回归检查（中断的部分结果不做检查）
+	if config.Baseline != "" && !report.Partial {
+		logger.Section("回归检查")
+		if baselineErr != nil {
+			logger.Error("加载基线报告失败: %v", baselineErr)
+			return exitError
+		}
+		if code := runComparison(baseline, report, config.Thresholds); code != exitOK {
+			return code
+		}
+	}
+
+	if report.Partial {
+		logger.Println("\n⚠️ 测试已中断，部分报告已生成")
+		return exitInterrupted
+	}
+	if sloFailed > 0 {
+		logger.Println("\n❌ 测试完成，SLO 检查未通过")
+		return exitCheckFailed
+	}
 	logger.Println("\n✅ 测试完成!")
+	return exitOK
 }
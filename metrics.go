@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===============================
+// Prometheus 指标
+// ===============================
+
+// latencyBuckets 延迟直方图分桶上限（秒）
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogram 固定分桶直方图
+type histogram struct {
+	counts []uint64 // 每个分桶的计数（非累计，输出时累加）
+	sum    float64
+	count  uint64
+}
+
+func newHistogram() histogram {
+	return histogram{counts: make([]uint64, len(latencyBuckets))}
+}
+
+// observe 记录一个样本（秒）
+func (h *histogram) observe(v float64) {
+	for i, upper := range latencyBuckets {
+		if v <= upper {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// endpointSeries 单个节点（节点 × 协议 × 目标）的指标
+type endpointSeries struct {
+	labels     string // 已格式化的标签，如 endpoint="A",ip="1.2.3.4",protocol="HTTP/2"
+	requests   uint64
+	errors     uint64
+	statuses   map[int]uint64
+	ttfb       histogram
+	cdnLatency histogram
+	serverTime histogram
+}
+
+// MetricsRegistry 守护模式累计的指标，按 Prometheus 文本格式输出
+type MetricsRegistry struct {
+	mu        sync.Mutex
+	series    []*endpointSeries // 保持配置顺序
+	byKey     map[string]*endpointSeries
+	rounds    uint64
+	lastRound time.Time
+}
+
+// NewMetricsRegistry 为每个测试组合预先注册指标，未发起请求前也能看到全部节点
+func NewMetricsRegistry(clients []EndpointClient) *MetricsRegistry {
+	m := &MetricsRegistry{byKey: make(map[string]*endpointSeries)}
+	for _, ec := range clients {
+		key := reportKey(ec.Endpoint, ec.Target)
+		if _, ok := m.byKey[key]; ok {
+			continue
+		}
+		s := &endpointSeries{
+			labels:     seriesLabels(ec.Endpoint, ec.Target),
+			statuses:   make(map[int]uint64),
+			ttfb:       newHistogram(),
+			cdnLatency: newHistogram(),
+			serverTime: newHistogram(),
+		}
+		m.series = append(m.series, s)
+		m.byKey[key] = s
+	}
+	return m
+}
+
+// seriesLabels 节点标签，配置 targets 时附加 target 标签
+func seriesLabels(endpoint Endpoint, target Target) string {
+	labels := fmt.Sprintf(`endpoint="%s",ip="%s",protocol="%s"`,
+		escapeLabel(endpoint.Name), escapeLabel(endpoint.IP), escapeLabel(endpoint.Protocol.String()))
+	if target.Name != "" {
+		labels += fmt.Sprintf(`,target="%s"`, escapeLabel(target.Name))
+	}
+	return labels
+}
+
+// escapeLabel 转义标签值中的反斜杠、双引号和换行
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// Observe 记录一轮测试的结果
+func (m *MetricsRegistry) Observe(results []EndpointResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, er := range results {
+		s, ok := m.byKey[reportKey(er.Endpoint, er.Target)]
+		if !ok {
+			continue
+		}
+		r := er.Result
+		s.requests++
+		if r.StatusCode != 0 {
+			s.statuses[r.StatusCode]++
+		}
+		if r.Error != "" {
+			s.errors++
+			continue
+		}
+		s.ttfb.observe(r.TTFB.Seconds())
+		if r.ServerTimeSource != "" {
+			s.serverTime.observe(r.XResponseTime / 1000)
+			s.cdnLatency.observe(r.CDNLatency / 1000)
+		}
+	}
+	m.rounds++
+	m.lastRound = time.Now()
+}
+
+// writeText 按 Prometheus 文本格式 (0.0.4) 输出全部指标
+func (m *MetricsRegistry) writeText(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	writeHeader(bw, "cdn_requests_total", "counter", "已发起的请求数")
+	for _, s := range m.series {
+		fmt.Fprintf(bw, "cdn_requests_total{%s} %d\n", s.labels, s.requests)
+	}
+
+	writeHeader(bw, "cdn_request_errors_total", "counter", "失败的请求数（连接失败、超时等）")
+	for _, s := range m.series {
+		fmt.Fprintf(bw, "cdn_request_errors_total{%s} %d\n", s.labels, s.errors)
+	}
+
+	writeHeader(bw, "cdn_responses_total", "counter", "按状态码统计的响应数")
+	for _, s := range m.series {
+		codes := make([]int, 0, len(s.statuses))
+		for code := range s.statuses {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(bw, "cdn_responses_total{%s,code=\"%d\"} %d\n", s.labels, code, s.statuses[code])
+		}
+	}
+
+	writeHistograms(bw, "cdn_ttfb_seconds", "TTFB（发起请求到收到首字节）", m.series, func(s *endpointSeries) *histogram { return &s.ttfb })
+	writeHistograms(bw, "cdn_latency_seconds", "CDN 延迟 = TTFB - 服务端耗时（仅统计解析到服务端耗时的请求）", m.series, func(s *endpointSeries) *histogram { return &s.cdnLatency })
+	writeHistograms(bw, "cdn_server_time_seconds", "服务端耗时（来源见 server_timing 配置）", m.series, func(s *endpointSeries) *histogram { return &s.serverTime })
+
+	writeHeader(bw, "cdn_monitor_rounds_total", "counter", "已完成的测试轮次")
+	fmt.Fprintf(bw, "cdn_monitor_rounds_total %d\n", m.rounds)
+	writeHeader(bw, "cdn_monitor_last_round_timestamp_seconds", "gauge", "最近一轮测试完成的时间（Unix 秒）")
+	lastRound := 0.0
+	if !m.lastRound.IsZero() {
+		lastRound = float64(m.lastRound.UnixMilli()) / 1000
+	}
+	fmt.Fprintf(bw, "cdn_monitor_last_round_timestamp_seconds %s\n", strconv.FormatFloat(lastRound, 'f', 3, 64))
+
+	return bw.Flush()
+}
+
+// writeHeader 输出指标的 HELP 和 TYPE 行
+func writeHeader(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// writeHistograms 输出所有节点的同一直方图指标
+func writeHistograms(w io.Writer, name, help string, series []*endpointSeries, get func(*endpointSeries) *histogram) {
+	writeHeader(w, name, "histogram", help)
+	for _, s := range series {
+		h := get(s)
+		var cumulative uint64
+		for i, upper := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, s.labels, formatFloat(upper), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, s.labels, h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, s.labels, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, s.labels, h.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// ServeHTTP 提供 /metrics
+func (m *MetricsRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := m.writeText(w); err != nil {
+		logger.Error("输出指标失败: %v", err)
+	}
+}
@@ -1,27 +1,78 @@
 package main
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 // 单次请求的测量结果
 type RequestResult struct {
 	Index         int           // 请求序号
 	TTFB          time.Duration // Time To First Byte（等待服务器响应时长）
-	XResponseTime float64       // x-source-response-time 响应头的值（ms）
+	XResponseTime float64       // 服务端耗时（ms），默认取 x-source-response-time，来源可配置
 	CDNLatency    float64       // CDN转发延迟 = TTFB - XResponseTime（ms）
 	StatusCode    int           // HTTP状态码
 	Reused        bool          // 是否复用连接
 	ActualProto   string        // 实际使用的协议版本（如 HTTP/1.1, HTTP/2.0）
 	Error         string        // 错误信息（如果有）
+
+	// 服务端耗时来源
+	ServerTimeSource string               // 命中的来源（响应头名或 Server-Timing:指标名）
+	ServerTiming     []ServerTimingMetric // 解析到的全部 Server-Timing 指标
+
+	// 缓存状态
+	CacheStatus string // 归一化缓存状态: HIT / MISS / STALE / BYPASS，无法识别时为空
+	CacheHeader string // 识别所依据的响应头
+
+	// 连接阶段分解（复用连接时建连/握手阶段为0）
+	TCPConnect    time.Duration // TCP 建连耗时
+	TLSHandshake  time.Duration // TLS 握手耗时
+	QUICHandshake time.Duration // QUIC 握手耗时（仅 HTTP/3，含 TLS）
+	RequestWrite  time.Duration // 请求发送耗时（获得连接 -> 请求写完）
+	ServerWait    time.Duration // 服务器等待耗时（请求写完 -> 首字节）
+	TLSResumed    bool          // 新建连接是否通过会话复用完成 TLS 握手
+	EarlyData     bool          // 是否使用了 QUIC 0-RTT 早期数据
+	TLSInfo       *TLSInfo      `json:"-"` // 新建连接的 TLS/证书信息（汇总到端点信息，不逐条导出）
+
+	// 证书校验
+	InsecureSkipVerify bool // 是否跳过了服务端证书校验（配置了 insecure_skip_verify）
+
+	// 开环压测（仅 load 模式）
+	SendDelay time.Duration // 发送滞后 = 实际发出时间 - 计划发送时间，已计入 TTFB / CDNLatency / TotalTime
+
+	// 并发测试（仅 concurrency > 1）
+	Stream     int // 流序号（1 ~ concurrency），同一轮的各流 Index 相同
+	BurstConns int // 本轮该节点实际新建的连接数（同一轮各流记录相同的值）
+
+	// 响应体下载（仅在开启 body.download 时记录）
+	TotalTime    time.Duration // 总耗时（发起请求 -> 响应体读完）
+	BodyTransfer time.Duration // 响应体传输耗时（首字节 -> 响应体读完）
+	BodyBytes    int64         // 读取的响应体字节数
+	Throughput   float64       // 有效吞吐量 = BodyBytes / TotalTime（Mbps）
+}
+
+// TLS 连接与证书信息
+type TLSInfo struct {
+	Version     string    `json:"version"`      // TLS 版本
+	CipherSuite string    `json:"cipher_suite"` // 加密套件
+	ALPN        string    `json:"alpn"`         // 协商的应用层协议
+	Subject     string    `json:"subject"`      // 证书主体
+	SANs        []string  `json:"sans"`         // 证书 SAN 列表
+	Issuer      string    `json:"issuer"`       // 签发者
+	NotAfter    time.Time `json:"not_after"`    // 证书过期时间
+	Fingerprint string    `json:"fingerprint"`  // 叶子证书 SHA-256 指纹（用于比较）
+	OCSPStapled bool      `json:"ocsp_stapled"` // 是否携带 OCSP Stapling 响应
 }
 
 // 汇总统计
 type Summary struct {
 	EndpointName string
 	Protocol     string
+	Target       string // 测试目标名称（未配置 targets 时为空）
 	TotalTests   int
 	SuccessCount int
 	FailCount    int
-	HasCDN       bool // 是否有 x-source-response-time 头（用于判断是否走CDN）
+	HasCDN       bool // 是否解析到服务端耗时（用于判断是否走CDN）
 
 	// TTFB 统计 (ms)
 	TTFBAvg float64
@@ -32,6 +83,11 @@ type Summary struct {
 	TTFBP95 float64
 	TTFBP99 float64
 
+	// TTFB 95% 置信区间（bootstrap 估计）
+	TTFBAvgCI ConfidenceInterval
+	TTFBP50CI ConfidenceInterval
+	TTFBP95CI ConfidenceInterval
+
 	// CDN延迟统计 (ms)
 	CDNLatencyAvg float64
 	CDNLatencyMin float64
@@ -41,6 +97,114 @@ type Summary struct {
 	CDNLatencyP95 float64
 	CDNLatencyP99 float64
 
-	// x-source-response-time 统计 (ms)
+	// 服务端耗时统计 (ms)
 	XResponseTimeAvg float64
+
+	// 连接阶段统计 (ms)，建连/握手只统计新建连接的样本
+	NewConnCount     int // 新建连接次数
+	TCPConnectAvg    float64
+	TCPConnectP95    float64
+	TLSHandshakeAvg  float64
+	TLSHandshakeP95  float64
+	QUICHandshakeAvg float64
+	QUICHandshakeP95 float64
+	RequestWriteAvg  float64
+	ServerWaitAvg    float64
+	ServerWaitP50    float64
+	ServerWaitP95    float64
+
+	// 完整握手与会话复用握手对比 (ms)，握手为 TLS 握手或 QUIC 握手
+	FullHandshakeCount  int
+	FullHandshakeAvg    float64
+	FullHandshakeP50    float64
+	FullTTFBAvg         float64
+	ResumedCount        int
+	ResumedHandshakeAvg float64
+	ResumedHandshakeP50 float64
+	ResumedTTFBAvg      float64
+	EarlyDataCount      int // 使用 0-RTT 的请求数
+
+	// 缓存命中统计
+	CacheHitCount     int
+	CacheMissCount    int
+	CacheStaleCount   int
+	CacheBypassCount  int
+	CacheUnknownCount int
+	CacheHitRatio     float64 // 命中率 = HIT / 可识别的请求数（0~1）
+	HitTTFBAvg        float64 // 命中请求 TTFB (ms)
+	HitTTFBP50        float64
+	HitTTFBP95        float64
+	MissTTFBAvg       float64 // 未命中请求 TTFB (ms)
+	MissTTFBP50       float64
+	MissTTFBP95       float64
+
+	// 冷/热连接 TTFB 对比 (ms)，按实际是否复用连接划分
+	// 冷连接样本数即 NewConnCount，热连接样本数为 SuccessCount - NewConnCount
+	ColdTTFBAvg float64
+	ColdTTFBP50 float64
+	ColdTTFBP95 float64
+	ColdTTFBP99 float64
+	WarmTTFBAvg float64
+	WarmTTFBP50 float64
+	WarmTTFBP95 float64
+	WarmTTFBP99 float64
+
+	// 下载统计，仅在开启 body.download 时有值
+	HasBody       bool    // 是否读取到响应体
+	BodyBytesAvg  float64 // 平均响应体大小（字节）
+	TotalTimeAvg  float64 // 总耗时 (ms)
+	TotalTimeP50  float64
+	TotalTimeP95  float64
+	ThroughputAvg float64 // 吞吐量 (Mbps)，低百分位即慢尾部
+	ThroughputP10 float64
+	ThroughputP50 float64
+	ThroughputP90 float64
+
+	// 开环压测，仅在 load 模式下有值（TTFB 等延迟从计划发送时间算起）
+	TargetRPS    float64 // 目标发送速率（每秒请求数）
+	AchievedRPS  float64 // 实际发送速率
+	SuccessRPS   float64 // 成功响应速率
+	SendDelayP50 float64 // 发送滞后 (ms)
+	SendDelayP99 float64
+	SendDelayMax float64
+
+	// 并发测试，仅在 concurrency > 1 时有值；单个流的延迟分布即上面的 TTFB 统计 (ms)
+	Concurrency     int     // 每轮同时发起的请求数
+	BurstCount      int     // 轮次数（有成功请求的轮次）
+	ConnsOpened     int     // 实际新建的连接数（按建连计数，所有轮次合计）
+	ConnsPerBurst   float64 // 平均每轮新建的连接数
+	BurstFastestAvg float64 // 每轮最快流 TTFB 的均值
+	BurstSlowestAvg float64 // 每轮最慢流 TTFB 的均值
+	BurstSpreadAvg  float64 // 轮内 TTFB 极差（最慢 - 最快）
+	BurstSpreadP50  float64
+	BurstSpreadP95  float64
+
+	// 自适应采样，仅在配置 adaptive 时有值
+	Rounds    int  // 实际采样的轮次（已收敛的节点提前停止）
+	Converged bool // TTFB P50 / P95 置信区间是否达到目标宽度
+}
+
+// Seq 结果序号，并发时附带流序号，如 "3#2"
+func (r RequestResult) Seq() string {
+	if r.Stream == 0 {
+		return strconv.Itoa(r.Index)
+	}
+	return strconv.Itoa(r.Index) + "#" + strconv.Itoa(r.Stream)
+}
+
+// Key 汇总在报告中的键，与 TestReport.Results 的键一致，用于跨报告匹配
+func (s Summary) Key() string {
+	key := s.EndpointName + " (" + s.Protocol + ")"
+	if s.Target != "" {
+		key += " [" + s.Target + "]"
+	}
+	return key
+}
+
+// Label 表格中显示的节点名称（配置 targets 时附带目标名）
+func (s Summary) Label() string {
+	if s.Target == "" {
+		return s.EndpointName
+	}
+	return s.EndpointName + " [" + s.Target + "]"
 }
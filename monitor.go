@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ===============================
+// 守护模式
+// ===============================
+
+// runMonitor 按固定周期持续测试，并在 /metrics 提供 Prometheus 指标，直到 Ctrl-C / SIGTERM
+func runMonitor(config *Config) int {
+	var err error
+
+	// 守护模式长期运行，只输出到控制台，避免日志文件无限增长
+	logger, err = NewLogger(config.OutputDir, false)
+	if err != nil {
+		fmt.Printf("❌ 初始化日志失败: %v\n", err)
+		return exitError
+	}
+	defer logger.Close()
+
+	logger.Println("🚀 CDN延迟测试工具 (守护模式)")
+	logger.Println("==============================")
+	logger.LogConfig(*config)
+	if config.LoadRPS > 0 {
+		logger.Println("⚠️ 守护模式每轮每个节点发送一个请求，忽略 load 配置")
+	}
+	if config.Duration > 0 || config.Adaptive.Enabled() {
+		logger.Println("⚠️ 守护模式持续运行，忽略 duration 和 adaptive 配置")
+	}
+
+	clients := newEndpointClients(config)
+	metrics := NewMetricsRegistry(clients)
+
+	// 先监听端口，地址被占用时直接退出
+	ln, err := net.Listen("tcp", config.MonitorListen)
+	if err != nil {
+		logger.Error("监听 %s 失败: %v", config.MonitorListen, err)
+		return exitError
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("指标服务异常退出: %v", err)
+		}
+	}()
+	logger.Printf("\n📡 指标地址: http://%s/metrics，每 %s 测试一轮，按 Ctrl-C 停止\n", ln.Addr(), config.MonitorInterval)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 以固定周期调度：一轮耗时超过周期时立即开始下一轮
+	for round := 1; ; round++ {
+		start := time.Now()
+		tasks := newRoundTasks(clients, round, config.ConnectionMode.coldRound(round), config.Concurrency)
+		results := runParallelRound(ctx, tasks, round, 0)
+		if ctx.Err() != nil {
+			// 被中断的轮次不计入指标
+			break
+		}
+		metrics.Observe(results)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(config.MonitorInterval - time.Since(start)):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	stop()
+	logger.Println("\n⏹️ 正在停止指标服务...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("停止指标服务失败: %v", err)
+	}
+	logger.Println("✅ 守护模式已停止")
+	return exitOK
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 )
@@ -25,11 +27,29 @@ func percentile(values []float64, p float64) float64 {
 	return sorted[index]
 }
 
+// 计算平均值
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// 将时长转换为毫秒
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000.0
+}
+
 // 计算汇总统计
-func calculateSummary(endpoint Endpoint, results []RequestResult) Summary {
+func calculateSummary(endpoint Endpoint, target Target, results []RequestResult) Summary {
 	summary := Summary{
 		EndpointName: endpoint.Name,
 		Protocol:     endpoint.Protocol.String(),
+		Target:       target.Name,
 		TotalTests:   len(results),
 	}
 
@@ -37,6 +57,14 @@ func calculateSummary(endpoint Endpoint, results []RequestResult) Summary {
 	var cdnLatencyValues []float64
 	var xResponseTimeSum float64
 	var hasXResponseTime bool
+	var tcpValues, tlsValues, quicValues []float64
+	var writeValues, waitValues []float64
+	var coldValues, warmValues []float64
+	var hitValues, missValues []float64
+	var fullHandshakes, resumedHandshakes []float64
+	var fullTTFB, resumedTTFB []float64
+	var totalValues, throughputValues []float64
+	var bodyBytesSum int64
 
 	for _, r := range results {
 		if r.Error != "" {
@@ -52,6 +80,67 @@ func calculateSummary(endpoint Endpoint, results []RequestResult) Summary {
 		if r.XResponseTime > 0 {
 			hasXResponseTime = true
 		}
+
+		if r.Reused {
+			warmValues = append(warmValues, ttfbMs)
+		} else {
+			coldValues = append(coldValues, ttfbMs)
+		}
+
+		switch r.CacheStatus {
+		case CacheHit:
+			summary.CacheHitCount++
+			hitValues = append(hitValues, ttfbMs)
+		case CacheMiss:
+			summary.CacheMissCount++
+			missValues = append(missValues, ttfbMs)
+		case CacheStale:
+			summary.CacheStaleCount++
+		case CacheBypass:
+			summary.CacheBypassCount++
+		default:
+			summary.CacheUnknownCount++
+		}
+
+		// 建连/握手阶段只在新建连接时存在
+		if !r.Reused {
+			summary.NewConnCount++
+			if r.TCPConnect > 0 {
+				tcpValues = append(tcpValues, durationMs(r.TCPConnect))
+			}
+			if r.TLSHandshake > 0 {
+				tlsValues = append(tlsValues, durationMs(r.TLSHandshake))
+			}
+			if r.QUICHandshake > 0 {
+				quicValues = append(quicValues, durationMs(r.QUICHandshake))
+			}
+
+			// 按是否会话复用区分握手耗时
+			handshake := r.TLSHandshake
+			if r.QUICHandshake > 0 {
+				handshake = r.QUICHandshake
+			}
+			if handshake > 0 {
+				if r.TLSResumed {
+					resumedHandshakes = append(resumedHandshakes, durationMs(handshake))
+					resumedTTFB = append(resumedTTFB, ttfbMs)
+				} else {
+					fullHandshakes = append(fullHandshakes, durationMs(handshake))
+					fullTTFB = append(fullTTFB, ttfbMs)
+				}
+			}
+		}
+		if r.EarlyData {
+			summary.EarlyDataCount++
+		}
+		writeValues = append(writeValues, durationMs(r.RequestWrite))
+		waitValues = append(waitValues, durationMs(r.ServerWait))
+
+		if r.TotalTime > 0 {
+			totalValues = append(totalValues, durationMs(r.TotalTime))
+			throughputValues = append(throughputValues, r.Throughput)
+			bodyBytesSum += r.BodyBytes
+		}
 	}
 
 	if len(ttfbValues) == 0 {
@@ -76,6 +165,7 @@ func calculateSummary(endpoint Endpoint, results []RequestResult) Summary {
 	summary.TTFBP90 = percentile(ttfbValues, 0.90)
 	summary.TTFBP95 = percentile(ttfbValues, 0.95)
 	summary.TTFBP99 = percentile(ttfbValues, 0.99)
+	summary.TTFBAvgCI, summary.TTFBP50CI, summary.TTFBP95CI = bootstrapCIs(ttfbValues)
 
 	// CDN延迟统计
 	var cdnSum float64
@@ -96,23 +186,175 @@ func calculateSummary(endpoint Endpoint, results []RequestResult) Summary {
 	summary.CDNLatencyP95 = percentile(cdnLatencyValues, 0.95)
 	summary.CDNLatencyP99 = percentile(cdnLatencyValues, 0.99)
 
-	// x-source-response-time 平均值
+	// 服务端耗时平均值
 	summary.XResponseTimeAvg = xResponseTimeSum / float64(len(ttfbValues))
 	summary.HasCDN = hasXResponseTime
 
+	// 连接阶段统计
+	summary.TCPConnectAvg = average(tcpValues)
+	summary.TCPConnectP95 = percentile(tcpValues, 0.95)
+	summary.TLSHandshakeAvg = average(tlsValues)
+	summary.TLSHandshakeP95 = percentile(tlsValues, 0.95)
+	summary.QUICHandshakeAvg = average(quicValues)
+	summary.QUICHandshakeP95 = percentile(quicValues, 0.95)
+	summary.RequestWriteAvg = average(writeValues)
+	summary.ServerWaitAvg = average(waitValues)
+	summary.ServerWaitP50 = percentile(waitValues, 0.50)
+	summary.ServerWaitP95 = percentile(waitValues, 0.95)
+
+	// 完整握手与会话复用握手统计
+	summary.FullHandshakeCount = len(fullHandshakes)
+	summary.FullHandshakeAvg = average(fullHandshakes)
+	summary.FullHandshakeP50 = percentile(fullHandshakes, 0.50)
+	summary.FullTTFBAvg = average(fullTTFB)
+	summary.ResumedCount = len(resumedHandshakes)
+	summary.ResumedHandshakeAvg = average(resumedHandshakes)
+	summary.ResumedHandshakeP50 = percentile(resumedHandshakes, 0.50)
+	summary.ResumedTTFBAvg = average(resumedTTFB)
+
+	// 缓存命中统计
+	classified := summary.SuccessCount - summary.CacheUnknownCount
+	if classified > 0 {
+		summary.CacheHitRatio = float64(summary.CacheHitCount) / float64(classified)
+	}
+	summary.HitTTFBAvg = average(hitValues)
+	summary.HitTTFBP50 = percentile(hitValues, 0.50)
+	summary.HitTTFBP95 = percentile(hitValues, 0.95)
+	summary.MissTTFBAvg = average(missValues)
+	summary.MissTTFBP50 = percentile(missValues, 0.50)
+	summary.MissTTFBP95 = percentile(missValues, 0.95)
+
+	// 冷/热连接统计
+	summary.ColdTTFBAvg = average(coldValues)
+	summary.ColdTTFBP50 = percentile(coldValues, 0.50)
+	summary.ColdTTFBP95 = percentile(coldValues, 0.95)
+	summary.ColdTTFBP99 = percentile(coldValues, 0.99)
+	summary.WarmTTFBAvg = average(warmValues)
+	summary.WarmTTFBP50 = percentile(warmValues, 0.50)
+	summary.WarmTTFBP95 = percentile(warmValues, 0.95)
+	summary.WarmTTFBP99 = percentile(warmValues, 0.99)
+
+	// 下载统计
+	if len(totalValues) > 0 {
+		summary.HasBody = true
+		summary.BodyBytesAvg = float64(bodyBytesSum) / float64(len(totalValues))
+		summary.TotalTimeAvg = average(totalValues)
+		summary.TotalTimeP50 = percentile(totalValues, 0.50)
+		summary.TotalTimeP95 = percentile(totalValues, 0.95)
+		summary.ThroughputAvg = average(throughputValues)
+		summary.ThroughputP10 = percentile(throughputValues, 0.10)
+		summary.ThroughputP50 = percentile(throughputValues, 0.50)
+		summary.ThroughputP90 = percentile(throughputValues, 0.90)
+	}
+
 	return summary
 }
 
+// 检查各测试组合的证书：同一测试目标下证书或 TLS 版本不一致、即将过期时返回告警
+// 不同测试目标可能是不同域名，证书本来就不同，只在目标内部比较
+func checkCertificates(rows []TLSRow, warnDays int, now time.Time) []string {
+	var warnings []string
+
+	// 按测试目标分组，目标内再按证书指纹和 TLS 版本分组
+	type group struct {
+		byFingerprint, byVersion map[string][]string
+		fingerprints, versions   []string
+	}
+	var targets []string
+	groups := make(map[string]*group)
+	for _, row := range rows {
+		if row.TLS == nil {
+			continue
+		}
+		g, ok := groups[row.Target]
+		if !ok {
+			g = &group{byFingerprint: make(map[string][]string), byVersion: make(map[string][]string)}
+			groups[row.Target] = g
+			targets = append(targets, row.Target)
+		}
+		label := fmt.Sprintf("%s (%s)", row.Endpoint.Name, row.Endpoint.Protocol)
+		if row.TLS.Fingerprint != "" {
+			if _, ok := g.byFingerprint[row.TLS.Fingerprint]; !ok {
+				g.fingerprints = append(g.fingerprints, row.TLS.Fingerprint)
+			}
+			g.byFingerprint[row.TLS.Fingerprint] = append(g.byFingerprint[row.TLS.Fingerprint], label)
+		}
+		if _, ok := g.byVersion[row.TLS.Version]; !ok {
+			g.versions = append(g.versions, row.TLS.Version)
+		}
+		g.byVersion[row.TLS.Version] = append(g.byVersion[row.TLS.Version], label)
+
+		// 证书过期检查（每个目标的证书各自检查）
+		if !row.TLS.NotAfter.IsZero() {
+			days := int(row.TLS.NotAfter.Sub(now).Hours() / 24)
+			if days < 0 {
+				warnings = append(warnings, fmt.Sprintf("%s (%s) 的证书已于 %s 过期",
+					row.Label, row.Endpoint.Protocol, row.TLS.NotAfter.Format("2006-01-02")))
+			} else if days < warnDays {
+				warnings = append(warnings, fmt.Sprintf("%s (%s) 的证书将在 %d 天后过期 (%s)",
+					row.Label, row.Endpoint.Protocol, days, row.TLS.NotAfter.Format("2006-01-02")))
+			}
+		}
+	}
+
+	for _, target := range targets {
+		g := groups[target]
+		scope := "各端点"
+		if target != "" {
+			scope = fmt.Sprintf("目标 %s 的各端点", target)
+		}
+		if len(g.fingerprints) > 1 {
+			var parts []string
+			for _, fp := range g.fingerprints {
+				parts = append(parts, fmt.Sprintf("[%s] %s", fp[:16], strings.Join(g.byFingerprint[fp], ", ")))
+			}
+			warnings = append(warnings, scope+"证书不一致: "+strings.Join(parts, "; "))
+		}
+		if len(g.versions) > 1 {
+			var parts []string
+			for _, v := range g.versions {
+				parts = append(parts, fmt.Sprintf("[%s] %s", v, strings.Join(g.byVersion[v], ", ")))
+			}
+			warnings = append(warnings, scope+" TLS 版本不一致: "+strings.Join(parts, "; "))
+		}
+	}
+
+	return warnings
+}
+
+// 检查跳过证书校验的请求：证书错误不会导致这些请求失败，需要在报告中明确标出
+func checkInsecure(summaries []Summary, results map[string][]RequestResult) []string {
+	var warnings []string
+	for _, s := range summaries {
+		rs := results[s.Key()]
+		skipped := 0
+		for _, r := range rs {
+			if r.InsecureSkipVerify {
+				skipped++
+			}
+		}
+		if skipped > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s (%s) 跳过了证书校验（%d/%d 个请求），证书无效时请求不会失败",
+				s.Label(), s.Protocol, skipped, len(rs)))
+		}
+	}
+	return warnings
+}
+
 // ===============================
 // 输出
 // ===============================
 
 // 打印详细结果表格
-func printDetailTable(endpoint Endpoint, results []RequestResult) {
-	fmt.Printf("\n📊 %s (%s @ %s) 详细结果:\n", endpoint.Name, endpoint.Protocol, endpoint.IP)
+func printDetailTable(endpoint Endpoint, target Target, results []RequestResult) {
+	if target.Name != "" {
+		fmt.Printf("\n📊 %s (%s @ %s) [%s] 详细结果:\n", endpoint.Name, endpoint.Protocol, endpoint.IP, target.Name)
+	} else {
+		fmt.Printf("\n📊 %s (%s @ %s) 详细结果:\n", endpoint.Name, endpoint.Protocol, endpoint.IP)
+	}
 
 	table := tablewriter.NewTable(os.Stdout,
-		tablewriter.WithHeader([]string{"序号", "状态码", "连接", "TTFB(ms)", "x-source-response-time(ms)", "CDN延迟(ms)", "错误"}),
+		tablewriter.WithHeader([]string{"序号", "状态码", "连接", "缓存", "TCP(ms)", "握手(ms)", "发送(ms)", "等待(ms)", "TTFB(ms)", "服务端(ms)", "CDN延迟(ms)", "错误"}),
 	)
 
 	for _, r := range results {
@@ -128,10 +370,26 @@ func printDetailTable(endpoint Endpoint, results []RequestResult) {
 			reusedStr = "Yes"
 		}
 
+		cacheStr := r.CacheStatus
+		if cacheStr == CacheUnknown {
+			cacheStr = "-"
+		}
+
+		// HTTP/3 显示 QUIC 握手，其余显示 TLS 握手
+		handshake := r.TLSHandshake
+		if r.QUICHandshake > 0 {
+			handshake = r.QUICHandshake
+		}
+
 		table.Append([]string{
-			fmt.Sprintf("%d", r.Index),
+			r.Seq(),
 			fmt.Sprintf("%d", r.StatusCode),
 			reusedStr,
+			cacheStr,
+			fmt.Sprintf("%.2f", durationMs(r.TCPConnect)),
+			fmt.Sprintf("%.2f", durationMs(handshake)),
+			fmt.Sprintf("%.2f", durationMs(r.RequestWrite)),
+			fmt.Sprintf("%.2f", durationMs(r.ServerWait)),
 			fmt.Sprintf("%.2f", ttfbMs),
 			fmt.Sprintf("%.2f", r.XResponseTime),
 			fmt.Sprintf("%.2f", r.CDNLatency),
@@ -152,11 +410,20 @@ func printSummaryTable(summaries []Summary) {
 			"TTFB均值", "TTFB-P50", "TTFB-P90", "TTFB-P99", "TTFB最小", "TTFB最大",
 			"CDN延迟均值", "CDN-P50", "CDN-P90", "CDN-P99",
 			"服务端均值",
+			"完整握手", "复用握手", "0-RTT",
 		}),
 	)
 
+	// 握手列格式: 均值(次数)，没有样本显示 "-"
+	handshake := func(avg float64, count int) string {
+		if count == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f (%d)", avg, count)
+	}
+
 	for _, s := range summaries {
-		// 如果没有 x-source-response-time 头，CDN相关列显示 "-"
+		// 如果没有解析到服务端耗时，CDN相关列显示 "-"
 		cdnLatencyAvg := "-"
 		cdnP50 := "-"
 		cdnP90 := "-"
@@ -171,7 +438,7 @@ func printSummaryTable(summaries []Summary) {
 		}
 
 		table.Append([]string{
-			s.EndpointName,
+			s.Label(),
 			s.Protocol,
 			fmt.Sprintf("%d/%d", s.SuccessCount, s.TotalTests),
 			fmt.Sprintf("%.2f", s.TTFBAvg),
@@ -185,12 +452,242 @@ func printSummaryTable(summaries []Summary) {
 			cdnP90,
 			cdnP99,
 			xResponseAvg,
+			handshake(s.FullHandshakeAvg, s.FullHandshakeCount),
+			handshake(s.ResumedHandshakeAvg, s.ResumedCount),
+			fmt.Sprintf("%d", s.EarlyDataCount),
 		})
 	}
 
 	table.Render()
 	fmt.Println("\n💡 说明: 所有时间单位均为毫秒(ms)")
 	fmt.Println("   - TTFB: Time To First Byte，等待服务器响应的时长")
-	fmt.Println("   - CDN延迟: TTFB - x-source-response-time，即网络传输 + CDN处理时间")
-	fmt.Println("   - 服务端均值: x-source-response-time 的平均值，即源站处理时间")
+	fmt.Println("   - CDN延迟: TTFB - 服务端耗时，即网络传输 + CDN处理时间")
+	fmt.Println("   - 服务端均值: 服务端耗时的平均值（来源见 server_timing 配置，默认 x-source-response-time），即源站处理时间")
+	fmt.Println("   - 完整握手/复用握手: 新建连接的 TLS(QUIC) 握手均值及次数，0-RTT 为使用早期数据的请求数")
+}
+
+// 打印连接阶段分解表格
+func printPhaseTable(summaries []Summary) {
+	fmt.Println("\n⏱️  连接阶段分解:")
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{
+			"节点", "协议", "新建连接",
+			"TCP均值", "TCP-P95", "TLS均值", "TLS-P95", "QUIC均值", "QUIC-P95",
+			"发送均值", "等待均值", "等待-P50", "等待-P95",
+		}),
+	)
+
+	// 没有样本的阶段显示 "-"
+	phase := func(v float64) string {
+		if v == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", v)
+	}
+
+	for _, s := range summaries {
+		table.Append([]string{
+			s.Label(),
+			s.Protocol,
+			fmt.Sprintf("%d", s.NewConnCount),
+			phase(s.TCPConnectAvg),
+			phase(s.TCPConnectP95),
+			phase(s.TLSHandshakeAvg),
+			phase(s.TLSHandshakeP95),
+			phase(s.QUICHandshakeAvg),
+			phase(s.QUICHandshakeP95),
+			fmt.Sprintf("%.2f", s.RequestWriteAvg),
+			fmt.Sprintf("%.2f", s.ServerWaitAvg),
+			fmt.Sprintf("%.2f", s.ServerWaitP50),
+			fmt.Sprintf("%.2f", s.ServerWaitP95),
+		})
+	}
+
+	table.Render()
+	fmt.Println("\n💡 说明: TCP/TLS/QUIC 只统计新建连接；等待 = 请求写完到收到首字节，即服务器处理 + 往返时间")
+}
+
+// 打印缓存命中统计表格
+func printCacheTable(summaries []Summary) {
+	fmt.Println("\n🗄️  缓存命中统计:")
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{
+			"节点", "协议", "命中率",
+			"HIT", "MISS", "STALE", "BYPASS", "未知",
+			"HIT-均值", "HIT-P50", "HIT-P95",
+			"MISS-均值", "MISS-P50", "MISS-P95",
+		}),
+	)
+
+	// 没有样本时显示 "-"
+	stat := func(count int, v float64) string {
+		if count == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", v)
+	}
+
+	for _, s := range summaries {
+		ratio := "-"
+		if s.SuccessCount > s.CacheUnknownCount {
+			ratio = fmt.Sprintf("%.1f%%", s.CacheHitRatio*100)
+		}
+		table.Append([]string{
+			s.Label(),
+			s.Protocol,
+			ratio,
+			fmt.Sprintf("%d", s.CacheHitCount),
+			fmt.Sprintf("%d", s.CacheMissCount),
+			fmt.Sprintf("%d", s.CacheStaleCount),
+			fmt.Sprintf("%d", s.CacheBypassCount),
+			fmt.Sprintf("%d", s.CacheUnknownCount),
+			stat(s.CacheHitCount, s.HitTTFBAvg),
+			stat(s.CacheHitCount, s.HitTTFBP50),
+			stat(s.CacheHitCount, s.HitTTFBP95),
+			stat(s.CacheMissCount, s.MissTTFBAvg),
+			stat(s.CacheMissCount, s.MissTTFBP50),
+			stat(s.CacheMissCount, s.MissTTFBP95),
+		})
+	}
+
+	table.Render()
+	fmt.Println("\n💡 说明: 命中率 = HIT / 可识别缓存状态的请求数；HIT/MISS 列为对应请求的 TTFB(ms)")
+}
+
+// 打印冷/热连接对比表格
+func printConnectionTable(summaries []Summary) {
+	fmt.Println("\n🧊 冷/热连接对比:")
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{
+			"节点", "协议",
+			"冷连接数", "冷-均值", "冷-P50", "冷-P95", "冷-P99",
+			"热连接数", "热-均值", "热-P50", "热-P95", "热-P99",
+		}),
+	)
+
+	for _, s := range summaries {
+		cold := []string{"0", "-", "-", "-", "-"}
+		if s.NewConnCount > 0 {
+			cold = []string{
+				fmt.Sprintf("%d", s.NewConnCount),
+				fmt.Sprintf("%.2f", s.ColdTTFBAvg),
+				fmt.Sprintf("%.2f", s.ColdTTFBP50),
+				fmt.Sprintf("%.2f", s.ColdTTFBP95),
+				fmt.Sprintf("%.2f", s.ColdTTFBP99),
+			}
+		}
+		warmCount := s.SuccessCount - s.NewConnCount
+		warm := []string{"0", "-", "-", "-", "-"}
+		if warmCount > 0 {
+			warm = []string{
+				fmt.Sprintf("%d", warmCount),
+				fmt.Sprintf("%.2f", s.WarmTTFBAvg),
+				fmt.Sprintf("%.2f", s.WarmTTFBP50),
+				fmt.Sprintf("%.2f", s.WarmTTFBP95),
+				fmt.Sprintf("%.2f", s.WarmTTFBP99),
+			}
+		}
+
+		row := append([]string{s.Label(), s.Protocol}, cold...)
+		table.Append(append(row, warm...))
+	}
+
+	table.Render()
+	fmt.Println("\n💡 说明: 冷连接 = 新建 TCP/TLS 或 QUIC 连接（首次访问的真实体验），热连接 = 复用已有连接，均为 TTFB(ms)")
+}
+
+// 打印下载耗时与吞吐量表格
+func printTransferTable(summaries []Summary) {
+	fmt.Println("\n📦 下载耗时与吞吐量:")
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{
+			"节点", "协议", "平均大小(KB)",
+			"总耗时均值", "总耗时-P50", "总耗时-P95",
+			"吞吐均值(Mbps)", "吞吐-P10", "吞吐-P50", "吞吐-P90",
+		}),
+	)
+
+	for _, s := range summaries {
+		if !s.HasBody {
+			table.Append([]string{s.Label(), s.Protocol, "-", "-", "-", "-", "-", "-", "-", "-"})
+			continue
+		}
+		table.Append([]string{
+			s.Label(),
+			s.Protocol,
+			fmt.Sprintf("%.1f", s.BodyBytesAvg/1024),
+			fmt.Sprintf("%.2f", s.TotalTimeAvg),
+			fmt.Sprintf("%.2f", s.TotalTimeP50),
+			fmt.Sprintf("%.2f", s.TotalTimeP95),
+			fmt.Sprintf("%.2f", s.ThroughputAvg),
+			fmt.Sprintf("%.2f", s.ThroughputP10),
+			fmt.Sprintf("%.2f", s.ThroughputP50),
+			fmt.Sprintf("%.2f", s.ThroughputP90),
+		})
+	}
+
+	table.Render()
+	fmt.Println("\n💡 说明: 总耗时 = 发起请求到响应体读完；吞吐量 = 响应体大小 / 总耗时，P10 代表较慢的 10% 请求")
+}
+
+// 打印 TLS 与证书信息表格
+func printTLSTable(rows []TLSRow) {
+	fmt.Println("\n🔒 TLS 与证书信息:")
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{
+			"节点", "协议", "TLS版本", "加密套件", "ALPN",
+			"证书主体", "签发者", "过期时间", "OCSP", "指纹", "证书校验",
+		}),
+	)
+
+	for _, row := range rows {
+		ep := row.Endpoint
+		if row.TLS == nil {
+			version := "-"
+			if !parseProtocol(ep.Protocol).Secure() {
+				version = "明文"
+			}
+			table.Append([]string{row.Label, ep.Protocol, version, "-", "-", "-", "-", "-", "-", "-", ep.Verification()})
+			continue
+		}
+		ocsp := "No"
+		if row.TLS.OCSPStapled {
+			ocsp = "Yes"
+		}
+		fingerprint := row.TLS.Fingerprint
+		if len(fingerprint) > 16 {
+			fingerprint = fingerprint[:16]
+		}
+		table.Append([]string{
+			row.Label,
+			ep.Protocol,
+			row.TLS.Version,
+			row.TLS.CipherSuite,
+			row.TLS.ALPN,
+			row.TLS.Subject,
+			row.TLS.Issuer,
+			row.TLS.NotAfter.Format("2006-01-02"),
+			ocsp,
+			fingerprint,
+			ep.Verification(),
+		})
+	}
+
+	table.Render()
+}
+
+// 打印告警信息
+func printWarnings(warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Println("\n⚠️  告警:")
+	for _, w := range warnings {
+		fmt.Printf("   - %s\n", w)
+	}
 }
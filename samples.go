@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ===============================
+// 原始样本导出（CSV / NDJSON）
+// ===============================
+
+// sampleRow 单次请求的扁平记录，耗时统一为毫秒，便于 pandas 等工具直接读取
+type sampleRow struct {
+	RunID          string  `json:"run_id"`
+	Round          int     `json:"round"`
+	Stream         int     `json:"stream"`
+	Endpoint       string  `json:"endpoint"`
+	IP             string  `json:"ip"`
+	Protocol       string  `json:"protocol"`
+	Target         string  `json:"target"`
+	ActualProto    string  `json:"actual_proto"`
+	Status         int     `json:"status"`
+	Reused         bool    `json:"reused"`
+	TLSResumed     bool    `json:"tls_resumed"`
+	EarlyData      bool    `json:"early_data"`
+	Insecure       bool    `json:"insecure_skip_verify"`
+	CacheStatus    string  `json:"cache_status"`
+	TCPMs          float64 `json:"tcp_ms"`
+	TLSMs          float64 `json:"tls_ms"`
+	QUICMs         float64 `json:"quic_ms"`
+	RequestWriteMs float64 `json:"request_write_ms"`
+	ServerWaitMs   float64 `json:"server_wait_ms"`
+	TTFBMs         float64 `json:"ttfb_ms"`
+	ServerTimeMs   float64 `json:"server_time_ms"`
+	CDNLatencyMs   float64 `json:"cdn_latency_ms"`
+	BodyTransferMs float64 `json:"body_transfer_ms"`
+	TotalMs        float64 `json:"total_ms"`
+	BodyBytes      int64   `json:"body_bytes"`
+	ThroughputMbps float64 `json:"throughput_mbps"`
+	SendDelayMs    float64 `json:"send_delay_ms"`
+	Error          string  `json:"error"`
+}
+
+// sampleColumns CSV 表头，顺序与 sampleRow.record 一致
+var sampleColumns = []string{
+	"run_id", "round", "stream", "endpoint", "ip", "protocol", "target", "actual_proto", "status",
+	"reused", "tls_resumed", "early_data", "insecure_skip_verify", "cache_status",
+	"tcp_ms", "tls_ms", "quic_ms", "request_write_ms", "server_wait_ms",
+	"ttfb_ms", "server_time_ms", "cdn_latency_ms", "body_transfer_ms", "total_ms",
+	"body_bytes", "throughput_mbps", "send_delay_ms", "error",
+}
+
+// record CSV 行
+func (r sampleRow) record() []string {
+	ms := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	return []string{
+		r.RunID,
+		strconv.Itoa(r.Round),
+		strconv.Itoa(r.Stream),
+		r.Endpoint,
+		r.IP,
+		r.Protocol,
+		r.Target,
+		r.ActualProto,
+		strconv.Itoa(r.Status),
+		strconv.FormatBool(r.Reused),
+		strconv.FormatBool(r.TLSResumed),
+		strconv.FormatBool(r.EarlyData),
+		strconv.FormatBool(r.Insecure),
+		r.CacheStatus,
+		ms(r.TCPMs),
+		ms(r.TLSMs),
+		ms(r.QUICMs),
+		ms(r.RequestWriteMs),
+		ms(r.ServerWaitMs),
+		ms(r.TTFBMs),
+		ms(r.ServerTimeMs),
+		ms(r.CDNLatencyMs),
+		ms(r.BodyTransferMs),
+		ms(r.TotalMs),
+		strconv.FormatInt(r.BodyBytes, 10),
+		strconv.FormatFloat(r.ThroughputMbps, 'f', 3, 64),
+		ms(r.SendDelayMs),
+		r.Error,
+	}
+}
+
+// sampleRows 按汇总顺序（节点 × 目标）和轮次展开报告中的所有请求
+func sampleRows(report *TestReport) []sampleRow {
+	// 节点 IP 从配置快照中查找（名称 + 协议唯一）
+	ips := make(map[string]string, len(report.Config.Endpoints))
+	for _, ep := range report.Config.Endpoints {
+		ips[ep.Name+"|"+ep.Protocol] = ep.IP
+	}
+
+	runID := report.RunID()
+	var rows []sampleRow
+	for _, s := range report.Summaries {
+		for _, r := range report.Results[s.Key()] {
+			rows = append(rows, sampleRow{
+				RunID:          runID,
+				Round:          r.Index,
+				Stream:         r.Stream,
+				Endpoint:       s.EndpointName,
+				IP:             ips[s.EndpointName+"|"+s.Protocol],
+				Protocol:       s.Protocol,
+				Target:         s.Target,
+				ActualProto:    r.ActualProto,
+				Status:         r.StatusCode,
+				Reused:         r.Reused,
+				TLSResumed:     r.TLSResumed,
+				EarlyData:      r.EarlyData,
+				Insecure:       r.InsecureSkipVerify,
+				CacheStatus:    r.CacheStatus,
+				TCPMs:          durationMs(r.TCPConnect),
+				TLSMs:          durationMs(r.TLSHandshake),
+				QUICMs:         durationMs(r.QUICHandshake),
+				RequestWriteMs: durationMs(r.RequestWrite),
+				ServerWaitMs:   durationMs(r.ServerWait),
+				TTFBMs:         durationMs(r.TTFB),
+				ServerTimeMs:   r.XResponseTime,
+				CDNLatencyMs:   r.CDNLatency,
+				BodyTransferMs: durationMs(r.BodyTransfer),
+				TotalMs:        durationMs(r.TotalTime),
+				BodyBytes:      r.BodyBytes,
+				ThroughputMbps: r.Throughput,
+				SendDelayMs:    durationMs(r.SendDelay),
+				Error:          r.Error,
+			})
+		}
+	}
+	return rows
+}
+
+// ExportCSV 导出 CSV 格式的原始样本，每行一个请求
+func ExportCSV(report *TestReport, outputDir string) (string, error) {
+	reportDir := filepath.Join(outputDir, "reports")
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return "", fmt.Errorf("创建报告目录失败: %w", err)
+	}
+	filePath := filepath.Join(reportDir, report.RunID()+".csv")
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("创建 CSV 文件失败: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write(sampleColumns)
+	for _, row := range sampleRows(report) {
+		w.Write(row.record())
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("写入 CSV 文件失败: %w", err)
+	}
+	return filePath, nil
+}
+
+// ExportNDJSON 导出 NDJSON 格式的原始样本，每行一个请求
+func ExportNDJSON(report *TestReport, outputDir string) (string, error) {
+	reportDir := filepath.Join(outputDir, "reports")
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return "", fmt.Errorf("创建报告目录失败: %w", err)
+	}
+	filePath := filepath.Join(reportDir, report.RunID()+".ndjson")
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("创建 NDJSON 文件失败: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, row := range sampleRows(report) {
+		if err := enc.Encode(row); err != nil {
+			return "", fmt.Errorf("写入 NDJSON 文件失败: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("写入 NDJSON 文件失败: %w", err)
+	}
+	return filePath, nil
+}
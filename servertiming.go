@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===============================
+// 服务端耗时解析
+// ===============================
+
+// TimingSource 服务端耗时来源（响应头及解析规则）
+type TimingSource struct {
+	Header  string         // 响应头名称
+	Metric  string         // Server-Timing 指标名（为空时取第一个带 dur 的指标）
+	Unit    string         // 数值单位: s / ms / us（值自带单位时以值为准）
+	Pattern *regexp.Regexp // 可选，取第一个捕获组作为数值
+}
+
+// 默认来源：x-source-response-time，单位秒
+var defaultTimingSources = []TimingSource{
+	{Header: "x-source-response-time", Unit: "s"},
+}
+
+// ServerTimingMetric Server-Timing 响应头中的单个指标
+type ServerTimingMetric struct {
+	Name        string  // 指标名
+	Duration    float64 // dur 参数（ms），没有时为 0
+	HasDuration bool    // 是否带 dur 参数
+	Description string  // desc 参数
+}
+
+// isServerTimingHeader 判断是否为标准 Server-Timing 头
+func isServerTimingHeader(name string) bool {
+	return strings.EqualFold(name, "Server-Timing")
+}
+
+// parseServerTiming 解析所有 Server-Timing 响应头
+// 格式: metric;dur=123.4;desc="xxx", metric2;dur=5
+func parseServerTiming(header http.Header) []ServerTimingMetric {
+	var metrics []ServerTimingMetric
+	for _, line := range header.Values("Server-Timing") {
+		for _, entry := range strings.Split(line, ",") {
+			parts := strings.Split(entry, ";")
+			name := strings.TrimSpace(parts[0])
+			if name == "" {
+				continue
+			}
+			metric := ServerTimingMetric{Name: name}
+			for _, param := range parts[1:] {
+				key, value, _ := strings.Cut(param, "=")
+				key = strings.ToLower(strings.TrimSpace(key))
+				value = strings.Trim(strings.TrimSpace(value), `"`)
+				switch key {
+				case "dur":
+					if v, err := strconv.ParseFloat(value, 64); err == nil {
+						metric.Duration = v
+						metric.HasDuration = true
+					}
+				case "desc":
+					metric.Description = value
+				}
+			}
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+// parseTimingValue 解析耗时数值并转换为毫秒
+// 值自带单位（如 "0.12s"、"35ms"）时以值为准，否则使用配置的单位
+func parseTimingValue(value string, unit string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return float64(d) / float64(time.Millisecond), true
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	switch unit {
+	case "s":
+		return v * 1000, true
+	case "us":
+		return v / 1000, true
+	default:
+		return v, true
+	}
+}
+
+// extractServerTime 按配置的来源顺序提取服务端耗时（ms），返回命中的来源描述
+func extractServerTime(header http.Header, metrics []ServerTimingMetric, sources []TimingSource) (float64, string, bool) {
+	for _, src := range sources {
+		// 标准 Server-Timing 头：取指定指标的 dur（单位固定为 ms）
+		if isServerTimingHeader(src.Header) {
+			for _, m := range metrics {
+				if !m.HasDuration {
+					continue
+				}
+				if src.Metric == "" || strings.EqualFold(m.Name, src.Metric) {
+					return m.Duration, "Server-Timing:" + m.Name, true
+				}
+			}
+			continue
+		}
+
+		value := header.Get(src.Header)
+		if value == "" {
+			continue
+		}
+		if src.Pattern != nil {
+			match := src.Pattern.FindStringSubmatch(value)
+			if len(match) < 2 {
+				continue
+			}
+			value = match[1]
+		}
+		if v, ok := parseTimingValue(value, src.Unit); ok {
+			return v, src.Header, true
+		}
+	}
+	return 0, "", false
+}
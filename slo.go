@@ -0,0 +1,303 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ===============================
+// SLO 断言
+// ===============================
+
+// SLO 服务等级目标断言，如 "ttfb_p99 < 300ms"、"status in [200,204]"
+type SLO struct {
+	Expr     string  // 原始表达式
+	Metric   string  // 指标名（见 summaryMetrics），或 "status"
+	Op       string  // <、<=、>、>=、in
+	Value    float64 // 阈值（ms 或 %）
+	Statuses []int   // status in [...] 允许的状态码
+	Global   bool    // 来自全局 slo 配置（否则来自端点配置）
+}
+
+// sloStatusMetric 状态码断言使用的指标名
+const sloStatusMetric = "status"
+
+var sloPattern = regexp.MustCompile(`^\s*([a-z0-9_]+)\s*(<=|>=|<|>|\bin\b)\s*(.+?)\s*$`)
+
+// parseSLO 解析 SLO 表达式
+func parseSLO(expr string) (SLO, error) {
+	m := sloPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return SLO{}, fmt.Errorf("无效的 SLO %q，格式应为 指标 运算符 值，如 ttfb_p99 < 300ms、status in [200,204]", expr)
+	}
+	slo := SLO{Expr: strings.TrimSpace(expr), Metric: m[1], Op: m[2]}
+
+	if slo.Metric == sloStatusMetric {
+		if slo.Op != "in" {
+			return SLO{}, fmt.Errorf("无效的 SLO %q，状态码只支持 status in [200,204] 的写法", expr)
+		}
+		statuses, err := parseStatusList(m[3])
+		if err != nil {
+			return SLO{}, fmt.Errorf("无效的 SLO %q: %w", expr, err)
+		}
+		slo.Statuses = statuses
+		return slo, nil
+	}
+
+	metric, ok := lookupMetric(slo.Metric)
+	if !ok {
+		return SLO{}, fmt.Errorf("SLO %q 中的指标 %q 未知，可选值: %s, %s", expr, slo.Metric, strings.Join(metricNames(), ", "), sloStatusMetric)
+	}
+	if slo.Op == "in" {
+		return SLO{}, fmt.Errorf("无效的 SLO %q，指标 %s 只支持 <、<=、>、>=", expr, slo.Metric)
+	}
+	value, err := parseSLOValue(m[3], metric.Unit)
+	if err != nil {
+		return SLO{}, fmt.Errorf("无效的 SLO %q: %w", expr, err)
+	}
+	slo.Value = value
+	return slo, nil
+}
+
+// parseSLOValue 解析阈值，单位需与指标一致：延迟可写 300ms / 0.3s / 300，比例可写 0.5% / 0.5
+func parseSLOValue(s, unit string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, nil
+	}
+	switch unit {
+	case "ms":
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("无效的时长 %q（示例: 300ms、0.5s）", s)
+		}
+		return durationMs(d), nil
+	case "%":
+		v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		if err != nil || !strings.HasSuffix(s, "%") {
+			return 0, fmt.Errorf("无效的百分比 %q（示例: 0.5%%）", s)
+		}
+		return v, nil
+	}
+	return 0, fmt.Errorf("无效的值 %q", s)
+}
+
+// parseStatusList 解析状态码列表，如 [200, 204]
+func parseStatusList(s string) ([]int, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("状态码列表需要用方括号，如 [200,204]")
+	}
+	var statuses []int
+	for _, part := range strings.Split(s[1:len(s)-1], ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("无效的状态码 %q", strings.TrimSpace(part))
+		}
+		statuses = append(statuses, code)
+	}
+	return statuses, nil
+}
+
+// parseSLOs 解析一组 SLO 表达式
+func parseSLOs(exprs []string, global bool) ([]SLO, error) {
+	slos := make([]SLO, 0, len(exprs))
+	for _, expr := range exprs {
+		slo, err := parseSLO(expr)
+		if err != nil {
+			return nil, err
+		}
+		slo.Global = global
+		slos = append(slos, slo)
+	}
+	return slos, nil
+}
+
+// mergeSLOs 合并全局与端点 SLO，端点对同一指标的断言覆盖全局断言
+func mergeSLOs(global, endpoint []SLO) []SLO {
+	own := make(map[string]bool, len(endpoint))
+	for _, s := range endpoint {
+		own[s.Metric] = true
+	}
+	merged := make([]SLO, 0, len(global)+len(endpoint))
+	for _, s := range global {
+		if !own[s.Metric] {
+			merged = append(merged, s)
+		}
+	}
+	return append(merged, endpoint...)
+}
+
+// SLO 判定结果
+const (
+	SLOPass    = "pass"
+	SLOFail    = "fail"
+	SLOSkipped = "skipped"
+)
+
+// SLOVerdict 单条 SLO 在某个节点上的判定结果
+type SLOVerdict struct {
+	Key      string // 节点键，同 Summary.Key()
+	Label    string // 显示名称
+	Protocol string
+	SLO      string // SLO 表达式
+	Scope    string // 全局 / 节点
+	Actual   string // 实际值
+	Result   string // pass / fail / skipped
+	Reason   string // 失败或跳过的原因
+}
+
+// SLOFailures 未通过的 SLO 数量（跳过的不计）
+func (r *TestReport) SLOFailures() int {
+	failed := 0
+	for _, v := range r.SLOVerdicts {
+		if v.Result == SLOFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// compareSLO 按运算符比较
+func compareSLO(actual float64, op string, limit float64) bool {
+	switch op {
+	case "<":
+		return actual < limit
+	case "<=":
+		return actual <= limit
+	case ">":
+		return actual > limit
+	case ">=":
+		return actual >= limit
+	}
+	return false
+}
+
+// evaluateSLOs 对单个节点的汇总和详细结果判定所有 SLO
+func evaluateSLOs(s Summary, results []RequestResult, slos []SLO) []SLOVerdict {
+	verdicts := make([]SLOVerdict, 0, len(slos))
+	for _, slo := range slos {
+		v := SLOVerdict{
+			Key:      s.Key(),
+			Label:    s.Label(),
+			Protocol: s.Protocol,
+			SLO:      slo.Expr,
+			Scope:    "节点",
+			Result:   SLOPass,
+		}
+		if slo.Global {
+			v.Scope = "全局"
+		}
+
+		if slo.Metric == sloStatusMetric {
+			evaluateStatusSLO(&v, slo, results)
+			verdicts = append(verdicts, v)
+			continue
+		}
+
+		metric, _ := lookupMetric(slo.Metric)
+		switch {
+		case metric.Unit == "ms" && s.SuccessCount == 0:
+			v.Actual = "-"
+			v.Result = SLOFail
+			v.Reason = "没有成功的请求"
+		case metric.NeedsCDN && !s.HasCDN:
+			v.Actual = "-"
+			v.Result = SLOSkipped
+			v.Reason = "未解析到服务端耗时"
+		default:
+			actual := metric.Value(s)
+			v.Actual = fmt.Sprintf("%.2f%s", actual, metric.Unit)
+			if !compareSLO(actual, slo.Op, slo.Value) {
+				v.Result = SLOFail
+			}
+		}
+		verdicts = append(verdicts, v)
+	}
+	return verdicts
+}
+
+// evaluateStatusSLO 检查所有收到响应的请求的状态码是否都在允许列表中
+func evaluateStatusSLO(v *SLOVerdict, slo SLO, results []RequestResult) {
+	allowed := make(map[int]bool, len(slo.Statuses))
+	for _, code := range slo.Statuses {
+		allowed[code] = true
+	}
+
+	counts := make(map[int]int)
+	for _, r := range results {
+		if r.StatusCode != 0 {
+			counts[r.StatusCode]++
+		}
+	}
+	if len(counts) == 0 {
+		v.Actual = "-"
+		v.Result = SLOFail
+		v.Reason = "没有收到响应"
+		return
+	}
+
+	codes := make([]int, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	parts := make([]string, len(codes))
+	var unexpected []string
+	for i, code := range codes {
+		parts[i] = fmt.Sprintf("%d×%d", code, counts[code])
+		if !allowed[code] {
+			unexpected = append(unexpected, strconv.Itoa(code))
+		}
+	}
+	v.Actual = strings.Join(parts, ", ")
+	if len(unexpected) > 0 {
+		v.Result = SLOFail
+		v.Reason = "出现非预期状态码 " + strings.Join(unexpected, ", ")
+	}
+}
+
+// 打印 SLO 判定表格，返回未通过的数量
+func printSLOTable(verdicts []SLOVerdict) int {
+	fmt.Println("\n🎯 SLO 检查:")
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{"节点", "协议", "SLO", "来源", "实际值", "结果", "说明"}),
+	)
+
+	failed := 0
+	for _, v := range verdicts {
+		result := "✅ 通过"
+		switch v.Result {
+		case SLOFail:
+			result = "❌ 未通过"
+			failed++
+		case SLOSkipped:
+			result = "⏭️ 跳过"
+		}
+		reason := v.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		table.Append([]string{v.Label, v.Protocol, v.SLO, v.Scope, v.Actual, result, reason})
+	}
+
+	table.Render()
+	fmt.Println("\n💡 说明: 端点 slo 对同一指标的断言覆盖全局 slo")
+	fmt.Println("   - CDN 延迟和服务端耗时断言在未解析到服务端耗时时跳过，不计为失败")
+	fmt.Println("   - status 只检查收到响应的请求，连接失败、超时请用 error_rate 约束")
+	if failed > 0 {
+		fmt.Printf("\n❌ SLO 检查未通过: %d/%d 项\n", failed, len(verdicts))
+	} else {
+		fmt.Printf("\n✅ SLO 检查通过: %d 项\n", len(verdicts))
+	}
+	return failed
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSLO(t *testing.T) {
+	tests := []struct {
+		expr     string
+		metric   string
+		op       string
+		value    float64
+		statuses []int
+		wantErr  bool
+	}{
+		{expr: "ttfb_p99 < 300ms", metric: "ttfb_p99", op: "<", value: 300},
+		{expr: "ttfb_p95<=0.5s", metric: "ttfb_p95", op: "<=", value: 500},
+		{expr: "  cdn_latency_avg > 20  ", metric: "cdn_latency_avg", op: ">", value: 20},
+		{expr: "error_rate < 0.5%", metric: "error_rate", op: "<", value: 0.5},
+		{expr: "success_rate >= 99.5", metric: "success_rate", op: ">=", value: 99.5},
+		{expr: "status in [200, 204]", metric: "status", op: "in", statuses: []int{200, 204}},
+
+		{expr: "ttfb_p99", wantErr: true},
+		{expr: "unknown_metric < 3", wantErr: true},
+		{expr: "ttfb_p99 < abc", wantErr: true},
+		{expr: "error_rate < 5ms", wantErr: true},
+		{expr: "ttfb_p99 in [200]", wantErr: true},
+		{expr: "status < 500", wantErr: true},
+		{expr: "status in 200,204", wantErr: true},
+		{expr: "status in [200, 99]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			slo, err := parseSLO(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望解析失败，实际得到 %+v", slo)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if slo.Metric != tt.metric || slo.Op != tt.op || slo.Value != tt.value || !slices.Equal(slo.Statuses, tt.statuses) {
+				t.Errorf("得到 %s %s %g %v, 期望 %s %s %g %v",
+					slo.Metric, slo.Op, slo.Value, slo.Statuses, tt.metric, tt.op, tt.value, tt.statuses)
+			}
+		})
+	}
+}
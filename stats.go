@@ -0,0 +1,370 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"sort"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ===============================
+// 统计检验
+// ===============================
+
+const (
+	bootstrapIterations = 1000 // bootstrap 重采样次数
+	confidenceLevel     = 0.95 // 置信水平
+	significanceLevel   = 0.05 // 显著性水平（p 值小于该值视为差异显著）
+	minTestSamples      = 5    // 秩和检验每组最少样本数，不足时不做检验
+)
+
+// ConfidenceInterval 置信区间
+type ConfidenceInterval struct {
+	Low  float64
+	High float64
+}
+
+// Width 区间宽度
+func (ci ConfidenceInterval) Width() float64 {
+	return ci.High - ci.Low
+}
+
+// bootstrapCIs 用 bootstrap 重采样同时估计均值、P50、P95 的置信区间
+// 使用固定种子，同一组数据每次得到相同结果
+func bootstrapCIs(values []float64) (mean, p50, p95 ConfidenceInterval) {
+	return bootstrap(values, true)
+}
+
+// bootstrapPercentileCIs 只估计 P50、P95 的置信区间（自适应采样每轮检查收敛时使用），
+// 与 bootstrapCIs 使用相同的重采样，结果一致
+func bootstrapPercentileCIs(values []float64) (p50, p95 ConfidenceInterval) {
+	_, p50, p95 = bootstrap(values, false)
+	return p50, p95
+}
+
+// bootstrap 对排序后的样本重采样：每次只统计各样本被抽中的次数，按累计次数找到 P50 / P95，
+// 不需要对重采样结果排序，每次重采样为 O(n)；withMean 为 false 时不计算均值，找到 P95 即停止
+func bootstrap(values []float64, withMean bool) (mean, p50, p95 ConfidenceInterval) {
+	n := len(values)
+	if n == 0 {
+		return
+	}
+	if n == 1 {
+		ci := ConfidenceInterval{values[0], values[0]}
+		return ci, ci, ci
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	// 与 sortedPercentile 取法相同的序号
+	rank50 := int(float64(n-1) * 0.50)
+	rank95 := int(float64(n-1) * 0.95)
+
+	rng := rand.New(rand.NewPCG(1, uint64(n)))
+	var means []float64
+	if withMean {
+		means = make([]float64, bootstrapIterations)
+	}
+	p50s := make([]float64, bootstrapIterations)
+	p95s := make([]float64, bootstrapIterations)
+	counts := make([]int, n)
+	for i := 0; i < bootstrapIterations; i++ {
+		clear(counts)
+		for j := 0; j < n; j++ {
+			counts[rng.IntN(n)]++
+		}
+		var seen int
+		var sum float64
+		for j, c := range counts {
+			if c == 0 {
+				continue
+			}
+			if seen <= rank50 && seen+c > rank50 {
+				p50s[i] = sorted[j]
+			}
+			if seen <= rank95 && seen+c > rank95 {
+				p95s[i] = sorted[j]
+				if !withMean {
+					break
+				}
+			}
+			seen += c
+			sum += sorted[j] * float64(c)
+		}
+		if withMean {
+			means[i] = sum / float64(n)
+		}
+	}
+
+	if withMean {
+		mean = percentileInterval(means)
+	}
+	return mean, percentileInterval(p50s), percentileInterval(p95s)
+}
+
+// percentileInterval 取重采样统计量的分位数作为置信区间
+func percentileInterval(stats []float64) ConfidenceInterval {
+	sort.Float64s(stats)
+	tail := (1 - confidenceLevel) / 2
+	return ConfidenceInterval{
+		Low:  sortedPercentile(stats, tail),
+		High: sortedPercentile(stats, 1-tail),
+	}
+}
+
+// sortedPercentile 已排序数据的百分位数（与 percentile 取法相同，省去复制和排序）
+func sortedPercentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+// MannWhitneyResult Mann–Whitney U 检验结果
+type MannWhitneyResult struct {
+	U     float64 // 第一组的 U 统计量
+	Z     float64 // 正态近似的 z 值
+	P     float64 // 双侧 p 值，样本不足时为 NaN
+	Valid bool    // 样本是否足够
+}
+
+// mannWhitneyU 两组独立样本的 Mann–Whitney U 检验（正态近似，含并列秩校正和连续性校正）
+func mannWhitneyU(a, b []float64) MannWhitneyResult {
+	n1, n2 := len(a), len(b)
+	if n1 < minTestSamples || n2 < minTestSamples {
+		return MannWhitneyResult{P: math.NaN()}
+	}
+
+	type sample struct {
+		value float64
+		first bool
+	}
+	all := make([]sample, 0, n1+n2)
+	for _, v := range a {
+		all = append(all, sample{v, true})
+	}
+	for _, v := range b {
+		all = append(all, sample{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// 计算秩，并列取平均秩
+	n := float64(n1 + n2)
+	var rankSum1, tieSum float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // 第 i+1 到第 j 名的平均秩
+		for k := i; k < j; k++ {
+			if all[k].first {
+				rankSum1 += rank
+			}
+		}
+		t := float64(j - i)
+		tieSum += t*t*t - t
+		i = j
+	}
+
+	u := rankSum1 - float64(n1)*float64(n1+1)/2
+	mean := float64(n1) * float64(n2) / 2
+	sigma := math.Sqrt(float64(n1) * float64(n2) / 12 * ((n + 1) - tieSum/(n*(n-1))))
+	if sigma == 0 {
+		// 所有样本相同
+		return MannWhitneyResult{U: u, P: 1, Valid: true}
+	}
+
+	diff := u - mean
+	switch {
+	case diff > 0.5:
+		diff -= 0.5
+	case diff < -0.5:
+		diff += 0.5
+	default:
+		diff = 0
+	}
+	z := diff / sigma
+	p := math.Erfc(math.Abs(z) / math.Sqrt2)
+	return MannWhitneyResult{U: u, Z: z, P: p, Valid: true}
+}
+
+// SignificanceTest 两组 TTFB 样本的显著性检验结果
+type SignificanceTest struct {
+	A           string  // 第一组（节点键或 "基线"）
+	B           string  // 第二组
+	MedianA     float64 // 第一组 TTFB 中位数 (ms)
+	MedianB     float64 // 第二组 TTFB 中位数 (ms)
+	SamplesA    int
+	SamplesB    int
+	U           float64
+	P           float64 // 双侧 p 值，样本不足时为 -1
+	PAdjusted   float64 // 多重比较校正后的 p 值（Holm），单次检验时等于 P，样本不足时为 -1
+	Valid       bool    // 样本是否足够
+	Significant bool    // 校正后的 p < significanceLevel
+}
+
+// Diff 中位数差值 B - A (ms)
+func (t SignificanceTest) Diff() float64 {
+	return t.MedianB - t.MedianA
+}
+
+// successTTFBs 成功请求的 TTFB (ms)
+func successTTFBs(results []RequestResult) []float64 {
+	var values []float64
+	for _, r := range results {
+		if r.Error == "" {
+			values = append(values, durationMs(r.TTFB))
+		}
+	}
+	return values
+}
+
+// testSignificance 对两组结果的 TTFB 做 Mann–Whitney U 检验
+func testSignificance(nameA, nameB string, a, b []RequestResult) SignificanceTest {
+	va, vb := successTTFBs(a), successTTFBs(b)
+	mw := mannWhitneyU(va, vb)
+	t := SignificanceTest{
+		A:        nameA,
+		B:        nameB,
+		MedianA:  percentile(va, 0.50),
+		MedianB:  percentile(vb, 0.50),
+		SamplesA: len(va),
+		SamplesB: len(vb),
+		U:        mw.U,
+		Valid:    mw.Valid,
+	}
+	if mw.Valid {
+		t.P = mw.P
+		t.PAdjusted = mw.P
+		t.Significant = mw.P < significanceLevel
+	} else {
+		t.P = -1
+		t.PAdjusted = -1
+	}
+	return t
+}
+
+// holmAdjust 对一组检验做 Holm–Bonferroni 多重比较校正，按校正后的 p 值重新判断显著性
+// 第 k 小的 p 值乘以 (m - k + 1)，并保持单调不减，样本不足的检验不计入 m
+func holmAdjust(tests []SignificanceTest) {
+	var valid []int
+	for i, t := range tests {
+		if t.Valid {
+			valid = append(valid, i)
+		}
+	}
+	sort.SliceStable(valid, func(i, j int) bool { return tests[valid[i]].P < tests[valid[j]].P })
+
+	m := len(valid)
+	adjusted := 0.0
+	for rank, i := range valid {
+		adjusted = math.Max(adjusted, math.Min(1, float64(m-rank)*tests[i].P))
+		tests[i].PAdjusted = adjusted
+		tests[i].Significant = adjusted < significanceLevel
+	}
+}
+
+// pairwiseSignificance 同一测试目标下的节点两两检验，对全部两两检验做 Holm 校正
+func pairwiseSignificance(summaries []Summary, results map[string][]RequestResult) []SignificanceTest {
+	var tests []SignificanceTest
+	for i := 0; i < len(summaries); i++ {
+		for j := i + 1; j < len(summaries); j++ {
+			a, b := summaries[i], summaries[j]
+			if a.Target != b.Target {
+				continue
+			}
+			tests = append(tests, testSignificance(a.Key(), b.Key(), results[a.Key()], results[b.Key()]))
+		}
+	}
+	holmAdjust(tests)
+	return tests
+}
+
+// baselineSignificance 当前报告与基线报告中同一节点的检验，对全部节点的检验做 Holm 校正
+func baselineSignificance(baseline, current *TestReport) []SignificanceTest {
+	var tests []SignificanceTest
+	for _, s := range current.Summaries {
+		key := s.Key()
+		base, ok := baseline.Results[key]
+		if !ok {
+			continue
+		}
+		tests = append(tests, testSignificance("基线", key, base, current.Results[key]))
+	}
+	holmAdjust(tests)
+	return tests
+}
+
+// 打印 TTFB 置信区间表格
+func printConfidenceTable(summaries []Summary) {
+	fmt.Println("\n📐 TTFB 置信区间 (95%):")
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{"节点", "协议", "样本", "均值", "均值 CI", "P50", "P50 CI", "P95", "P95 CI"}),
+	)
+
+	ci := func(c ConfidenceInterval) string {
+		return fmt.Sprintf("[%.2f, %.2f]", c.Low, c.High)
+	}
+
+	for _, s := range summaries {
+		table.Append([]string{
+			s.Label(),
+			s.Protocol,
+			fmt.Sprintf("%d", s.SuccessCount),
+			fmt.Sprintf("%.2f", s.TTFBAvg),
+			ci(s.TTFBAvgCI),
+			fmt.Sprintf("%.2f", s.TTFBP50),
+			ci(s.TTFBP50CI),
+			fmt.Sprintf("%.2f", s.TTFBP95),
+			ci(s.TTFBP95CI),
+		})
+	}
+
+	table.Render()
+	fmt.Printf("\n💡 说明: 置信区间由 %d 次 bootstrap 重采样估计（单位 ms），区间越窄结果越稳定；两个节点的区间重叠较多时差异可能只是抖动\n", bootstrapIterations)
+}
+
+// 打印显著性检验表格
+func printSignificanceTable(title string, tests []SignificanceTest) {
+	if len(tests) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", title)
+
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithHeader([]string{"A", "B", "A 中位数", "B 中位数", "差值 (B-A)", "U", "p 值", "校正 p 值", "结论"}),
+	)
+
+	for _, t := range tests {
+		u, p, adjusted, verdict := "-", "-", "-", "样本不足"
+		if t.Valid {
+			u = fmt.Sprintf("%.0f", t.U)
+			p = fmt.Sprintf("%.4f", t.P)
+			adjusted = fmt.Sprintf("%.4f", t.PAdjusted)
+			verdict = "不显著"
+			if t.Significant {
+				verdict = "显著 ⚠️"
+			}
+		}
+		table.Append([]string{
+			t.A,
+			t.B,
+			fmt.Sprintf("%.2f", t.MedianA),
+			fmt.Sprintf("%.2f", t.MedianB),
+			fmt.Sprintf("%+.2f", t.Diff()),
+			u,
+			p,
+			adjusted,
+			verdict,
+		})
+	}
+
+	table.Render()
+	fmt.Printf("\n💡 说明: Mann–Whitney U 秩和检验比较两组成功请求的 TTFB，校正 p < %g 视为差异显著；每组少于 %d 个样本时不做检验\n", significanceLevel, minTestSamples)
+	fmt.Println("   - 一次运行中有多次检验（节点两两比较，或每个节点各与基线比较一次），按 Holm 方法校正 p 值以控制整体误报率")
+}
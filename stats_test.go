@@ -0,0 +1,243 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"testing"
+	"time"
+)
+
+// seq 生成 from, from+1, ..., from+n-1
+func seq(from float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = from + float64(i)
+	}
+	return values
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  []float64
+		valid bool
+		u     float64
+		p     float64 // 期望 p 值（误差 1e-4）
+		maxP  float64 // 或 p 值上限（> 0 时检查）
+		minP  float64 // 或 p 值下限（> 0 时检查）
+	}{
+		// 完全分离: U = 0, z = (0 - 12.5 + 0.5) / sqrt(25/12 × 11)
+		{name: "完全分离", a: seq(1, 5), b: seq(6, 5), valid: true, u: 0, p: 0.012186},
+		{name: "交换两组", a: seq(6, 5), b: seq(1, 5), valid: true, u: 25, p: 0.012186},
+		{name: "两组相同", a: seq(1, 10), b: seq(1, 10), valid: true, u: 50, p: 1},
+		{name: "全部并列", a: []float64{3, 3, 3, 3, 3}, b: []float64{3, 3, 3, 3, 3, 3}, valid: true, u: 15, p: 1},
+		{name: "明显偏移", a: seq(1, 30), b: seq(101, 30), valid: true, u: 0, maxP: 1e-6},
+		{name: "交错分布", a: []float64{1, 3, 5, 7, 9, 11}, b: []float64{2, 4, 6, 8, 10, 12}, valid: true, u: 15, minP: 0.5},
+		{name: "样本不足", a: seq(1, 4), b: seq(1, 10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mannWhitneyU(tt.a, tt.b)
+			if got.Valid != tt.valid {
+				t.Fatalf("Valid = %v, 期望 %v", got.Valid, tt.valid)
+			}
+			if !tt.valid {
+				if !math.IsNaN(got.P) {
+					t.Errorf("样本不足时 P = %g, 期望 NaN", got.P)
+				}
+				return
+			}
+			if got.U != tt.u {
+				t.Errorf("U = %g, 期望 %g", got.U, tt.u)
+			}
+			switch {
+			case tt.maxP > 0:
+				if got.P >= tt.maxP {
+					t.Errorf("P = %g, 期望 < %g", got.P, tt.maxP)
+				}
+			case tt.minP > 0:
+				if got.P <= tt.minP {
+					t.Errorf("P = %g, 期望 > %g", got.P, tt.minP)
+				}
+			case math.Abs(got.P-tt.p) > 1e-4:
+				t.Errorf("P = %.6f, 期望 %.6f", got.P, tt.p)
+			}
+		})
+	}
+}
+
+func TestBootstrapCIs(t *testing.T) {
+	t.Run("空样本", func(t *testing.T) {
+		mean, p50, p95 := bootstrapCIs(nil)
+		if mean != (ConfidenceInterval{}) || p50 != (ConfidenceInterval{}) || p95 != (ConfidenceInterval{}) {
+			t.Errorf("空样本应返回零值区间，实际 %v %v %v", mean, p50, p95)
+		}
+	})
+
+	t.Run("单个样本", func(t *testing.T) {
+		want := ConfidenceInterval{42, 42}
+		mean, p50, p95 := bootstrapCIs([]float64{42})
+		if mean != want || p50 != want || p95 != want {
+			t.Errorf("单个样本应退化为 [42, 42]，实际 %v %v %v", mean, p50, p95)
+		}
+	})
+
+	t.Run("样本全部相同", func(t *testing.T) {
+		values := []float64{7, 7, 7, 7, 7, 7, 7, 7}
+		for _, ci := range ciList(bootstrapCIs(values)) {
+			if ci.Low != 7 || ci.High != 7 {
+				t.Errorf("区间 = %v, 期望 [7, 7]", ci)
+			}
+		}
+	})
+
+	t.Run("区间包含点估计", func(t *testing.T) {
+		values := seq(1, 200)
+		mean, p50, p95 := bootstrapCIs(values)
+		for _, c := range []struct {
+			name     string
+			ci       ConfidenceInterval
+			estimate float64
+		}{
+			{"均值", mean, average(values)},
+			{"P50", p50, percentile(values, 0.50)},
+			{"P95", p95, percentile(values, 0.95)},
+		} {
+			if c.ci.Width() <= 0 || c.ci.Low > c.estimate || c.ci.High < c.estimate {
+				t.Errorf("%s 区间 %v 应包含点估计 %g 且宽度大于 0", c.name, c.ci, c.estimate)
+			}
+		}
+		// 均值的标准误约为 57.7 / sqrt(200) ≈ 4.1，95% 区间宽度约 16
+		if w := mean.Width(); w < 10 || w > 22 {
+			t.Errorf("均值区间宽度 = %.2f, 期望约 16", w)
+		}
+	})
+
+	t.Run("与排序重采样一致", func(t *testing.T) {
+		values := []float64{12, 15, 11, 30, 14, 13, 18, 22, 16, 12, 19, 25, 40, 9, 17}
+		mean, p50, p95 := bootstrapCIs(values)
+		wantMean, wantP50, wantP95 := sortedBootstrap(values)
+		if p50 != wantP50 || p95 != wantP95 {
+			t.Errorf("P50 / P95 区间 = %v %v, 期望 %v %v", p50, p95, wantP50, wantP95)
+		}
+		if math.Abs(mean.Low-wantMean.Low) > 1e-9 || math.Abs(mean.High-wantMean.High) > 1e-9 {
+			t.Errorf("均值区间 = %v, 期望 %v", mean, wantMean)
+		}
+
+		// 只估计分位数时结果相同
+		if gotP50, gotP95 := bootstrapPercentileCIs(values); gotP50 != p50 || gotP95 != p95 {
+			t.Errorf("bootstrapPercentileCIs = %v %v, 期望与 bootstrapCIs 相同 %v %v", gotP50, gotP95, p50, p95)
+		}
+	})
+
+	t.Run("固定种子结果可复现", func(t *testing.T) {
+		values := []float64{12, 15, 11, 30, 14, 13, 18, 22, 16, 12, 19, 25}
+		m1, p501, p951 := bootstrapCIs(values)
+		m2, p502, p952 := bootstrapCIs(values)
+		if m1 != m2 || p501 != p502 || p951 != p952 {
+			t.Errorf("同一组数据两次结果不同: %v %v %v / %v %v %v", m1, p501, p951, m2, p502, p952)
+		}
+	})
+}
+
+// sortedBootstrap 按定义逐次排序重采样结果，使用与 bootstrap 相同的随机序列，作为对照
+func sortedBootstrap(values []float64) (mean, p50, p95 ConfidenceInterval) {
+	n := len(values)
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rng := rand.New(rand.NewPCG(1, uint64(n)))
+	means := make([]float64, bootstrapIterations)
+	p50s := make([]float64, bootstrapIterations)
+	p95s := make([]float64, bootstrapIterations)
+	sample := make([]float64, n)
+	for i := 0; i < bootstrapIterations; i++ {
+		for j := range sample {
+			sample[j] = sorted[rng.IntN(n)]
+		}
+		sort.Float64s(sample)
+		means[i] = average(sample)
+		p50s[i] = sortedPercentile(sample, 0.50)
+		p95s[i] = sortedPercentile(sample, 0.95)
+	}
+	return percentileInterval(means), percentileInterval(p50s), percentileInterval(p95s)
+}
+
+// ciList 把 bootstrapCIs 的三个返回值转为切片
+func ciList(mean, p50, p95 ConfidenceInterval) []ConfidenceInterval {
+	return []ConfidenceInterval{mean, p50, p95}
+}
+
+func TestHolmAdjust(t *testing.T) {
+	tests := []SignificanceTest{
+		{A: "a", P: 0.01, PAdjusted: 0.01, Valid: true},
+		{A: "b", P: 0.04, PAdjusted: 0.04, Valid: true},
+		{A: "c", P: -1, PAdjusted: -1},
+		{A: "d", P: 0.03, PAdjusted: 0.03, Valid: true},
+	}
+	holmAdjust(tests)
+
+	// 三个有效检验: 0.01 × 3 = 0.03，0.03 × 2 = 0.06，0.04 × 1 = 0.04 → 保持单调取 0.06
+	want := map[string]struct {
+		p           float64
+		significant bool
+	}{
+		"a": {0.03, true},
+		"b": {0.06, false},
+		"c": {-1, false},
+		"d": {0.06, false},
+	}
+	for _, tt := range tests {
+		w := want[tt.A]
+		if math.Abs(tt.PAdjusted-w.p) > 1e-12 || tt.Significant != w.significant {
+			t.Errorf("%s: 校正后 p = %g（显著 %v）, 期望 %g（显著 %v）", tt.A, tt.PAdjusted, tt.Significant, w.p, w.significant)
+		}
+	}
+}
+
+// ttfbResults 由 TTFB 毫秒数构造成功请求
+func ttfbResults(values []float64) []RequestResult {
+	results := make([]RequestResult, len(values))
+	for i, v := range values {
+		results[i] = RequestResult{Index: i + 1, TTFB: time.Duration(v * float64(time.Millisecond))}
+	}
+	return results
+}
+
+func TestBaselineSignificanceHolm(t *testing.T) {
+	baseline := &TestReport{Results: map[string][]RequestResult{}}
+	current := &TestReport{Results: map[string][]RequestResult{}}
+	// 三个节点的当前结果相对基线分别明显变慢、略有变化、完全相同
+	for _, n := range []struct {
+		name  string
+		shift float64
+	}{{"变慢", 100}, {"抖动", 3}, {"不变", 0}} {
+		s := Summary{EndpointName: n.name, Protocol: "HTTP/2"}
+		current.Summaries = append(current.Summaries, s)
+		baseline.Results[s.Key()] = ttfbResults(seq(10, 12))
+		current.Results[s.Key()] = ttfbResults(seq(10+n.shift, 12))
+	}
+
+	tests := baselineSignificance(baseline, current)
+	if len(tests) != 3 {
+		t.Fatalf("检验数 = %d, 期望 3", len(tests))
+	}
+	raw := make([]SignificanceTest, len(tests))
+	for i, tt := range tests {
+		raw[i] = testSignificance(tt.A, tt.B, baseline.Results[tt.B], current.Results[tt.B])
+	}
+	holmAdjust(raw)
+	for i, tt := range tests {
+		if tt.PAdjusted != raw[i].PAdjusted || tt.Significant != raw[i].Significant {
+			t.Errorf("%s: 校正 p = %g（显著 %v）, 期望对全部节点做 Holm 校正 %g（显著 %v）",
+				tt.B, tt.PAdjusted, tt.Significant, raw[i].PAdjusted, raw[i].Significant)
+		}
+	}
+	// 最小的 p 值乘以检验次数
+	if got, want := tests[0].PAdjusted, math.Min(1, 3*tests[0].P); math.Abs(got-want) > 1e-12 {
+		t.Errorf("变慢: 校正 p = %g, 期望 3 × %g = %g", got, tests[0].P, want)
+	}
+	if !tests[0].Significant || tests[2].Significant {
+		t.Errorf("显著性 = %v / %v, 期望变慢显著、不变不显著", tests[0].Significant, tests[2].Significant)
+	}
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ===============================
+// 配置校验
+// ===============================
+
+// ConfigError 单个配置问题
+type ConfigError struct {
+	Line  int    // YAML 行号（来自命令行参数时为 0）
+	Field string // 配置项路径，如 endpoints[1].protocol
+	Msg   string
+}
+
+func (e ConfigError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("第 %d 行 %s: %s", e.Line, e.Field, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Msg)
+}
+
+// ConfigErrors 配置校验发现的全部问题
+type ConfigErrors []ConfigError
+
+func (errs ConfigErrors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "配置校验失败，共 %d 个问题:", len(errs))
+	for _, e := range errs {
+		b.WriteString("\n  - ")
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
+
+// sortByLine 按行号排序，来自命令行参数的问题（无行号）排在最后
+func (errs ConfigErrors) sortByLine() {
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line == 0 || errs[j].Line == 0 {
+			return errs[j].Line == 0 && errs[i].Line != 0
+		}
+		return errs[i].Line < errs[j].Line
+	})
+}
+
+// yaml.v3 未知字段错误，如 "line 5: field prot not found in type main.yamlEndpoint"
+var (
+	unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type`)
+	yamlLinePattern     = regexp.MustCompile(`^line (\d+): (.*)$`)
+)
+
+// typeErrorsToConfigErrors 转换 yaml 解码时收集的类型错误（未知配置项、类型不匹配）
+func typeErrorsToConfigErrors(te *yaml.TypeError) ConfigErrors {
+	var errs ConfigErrors
+	for _, msg := range te.Errors {
+		if m := unknownFieldPattern.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			errs = append(errs, ConfigError{Line: line, Field: m[2], Msg: "未知配置项（请检查拼写和缩进）"})
+			continue
+		}
+		if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			errs = append(errs, ConfigError{Line: line, Field: "-", Msg: m[2]})
+			continue
+		}
+		errs = append(errs, ConfigError{Field: "-", Msg: msg})
+	}
+	return errs
+}
+
+// yamlLocator 根据配置项路径查找 YAML 行号
+type yamlLocator struct {
+	root       *yaml.Node
+	overridden map[string]bool // 被命令行参数覆盖的顶层配置项，不再对应文件中的行
+}
+
+// line 返回路径对应节点的行号，路径元素为 map 键（string）或列表下标（int）
+// 路径不完整存在时（如缺少的字段）返回最深一层已存在节点的行号
+func (l yamlLocator) line(path ...interface{}) int {
+	if l.root == nil || len(path) == 0 {
+		return 0
+	}
+	if key, ok := path[0].(string); ok && l.overridden[key] {
+		return 0
+	}
+
+	node := l.root
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return 0
+		}
+		node = node.Content[0]
+	}
+	for _, p := range path {
+		var next *yaml.Node
+		switch key := p.(type) {
+		case string:
+			if node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value == key {
+						next = node.Content[i+1]
+						break
+					}
+				}
+			}
+		case int:
+			if node.Kind == yaml.SequenceNode && key < len(node.Content) {
+				next = node.Content[key]
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return node.Line
+}
+
+// configValidator 收集配置问题
+type configValidator struct {
+	loc  yamlLocator
+	errs ConfigErrors
+}
+
+// add 记录问题，field 为显示用路径，path 用于查找行号
+func (v *configValidator) add(field string, msg string, path ...interface{}) {
+	v.errs = append(v.errs, ConfigError{Line: v.loc.line(path...), Field: field, Msg: msg})
+}
+
+// checkDuration 校验时长字符串，允许为空（使用默认值）
+func (v *configValidator) checkDuration(field, value string, allowZero bool, path ...interface{}) {
+	if value == "" {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	switch {
+	case err != nil:
+		v.add(field, fmt.Sprintf("无效的时长 %q（示例: 30s、500ms）", value), path...)
+	case d < 0 || (d == 0 && !allowZero):
+		v.add(field, fmt.Sprintf("时长必须大于 0，当前为 %q", value), path...)
+	}
+}
+
+// checkPath 校验请求路径，允许为空
+func (v *configValidator) checkPath(field, value string, path ...interface{}) {
+	if value != "" && !strings.HasPrefix(value, "/") {
+		v.add(field, fmt.Sprintf("路径必须以 / 开头，当前为 %q", value), path...)
+	}
+}
+
+// checkCerts 校验证书配置：文件可读取、证书与私钥成对出现且匹配
+func (v *configValidator) checkCerts(field string, c yamlCerts, path ...interface{}) {
+	at := func(key string) []interface{} {
+		return append(append([]interface{}{}, path...), key)
+	}
+	if c.CAFile != "" {
+		if _, err := loadCertPool(c.CAFile); err != nil {
+			v.add(field+".ca_file", err.Error(), at("ca_file")...)
+		}
+	}
+	switch {
+	case c.ClientCert == "" && c.ClientKey == "":
+	case c.ClientCert == "":
+		v.add(field+".client_cert", "配置了 client_key 时需要同时配置 client_cert", at("client_key")...)
+	case c.ClientKey == "":
+		v.add(field+".client_key", "配置了 client_cert 时需要同时配置 client_key", at("client_cert")...)
+	default:
+		if _, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey); err != nil {
+			v.add(field+".client_cert", fmt.Sprintf("加载客户端证书失败: %v", err), at("client_cert")...)
+		}
+	}
+}
+
+// validateConfig 校验 YAML 配置，返回全部问题（没有问题时为 nil）
+func validateConfig(yc *yamlConfig, loc yamlLocator) ConfigErrors {
+	v := &configValidator{loc: loc}
+
+	// 基本参数
+	if yc.Domain == "" {
+		// 所有目标都配置了 domain 时可以省略全局 domain
+		missing := len(yc.Targets) == 0
+		for _, t := range yc.Targets {
+			if t.Domain == "" {
+				missing = true
+			}
+		}
+		if missing {
+			v.add("domain", "不能为空", "domain")
+		}
+	}
+	v.checkPath("path", yc.Path, "path")
+	// 开环压测、按时长测试、配置了 adaptive.max_count 时不使用 test_count
+	roundsBounded := yc.Load.RPS > 0 || yc.Duration != "" || (yc.Adaptive.CIWidth != "" && yc.Adaptive.MaxCount > 0)
+	if yc.TestCount < 0 || (yc.TestCount == 0 && !roundsBounded) {
+		v.add("test_count", fmt.Sprintf("必须大于 0，当前为 %d", yc.TestCount), "test_count")
+	}
+	v.checkDuration("timeout", yc.Timeout, false, "timeout")
+	v.checkDuration("interval", yc.Interval, true, "interval")
+	if yc.ConnMode != "" {
+		if _, ok := lookupConnectionMode(yc.ConnMode); !ok {
+			v.add("connection_mode", fmt.Sprintf("未知连接模式 %q，可选值: warm, cold, mixed", yc.ConnMode), "connection_mode")
+		}
+	}
+
+	// 并发测试
+	if yc.Concurrency < 0 {
+		v.add("concurrency", fmt.Sprintf("不能小于 0，当前为 %d", yc.Concurrency), "concurrency")
+	}
+	if yc.Multiplex != "" {
+		if _, ok := lookupMultiplex(yc.Multiplex); !ok {
+			v.add("multiplex", fmt.Sprintf("未知连接复用方式 %q，可选值: single_conn, per_request_conn", yc.Multiplex), "multiplex")
+		}
+	}
+	if yc.Concurrency > 1 && yc.Load.RPS > 0 {
+		v.add("concurrency", "开环压测按速率发送，不使用 concurrency", "concurrency")
+	}
+
+	// 按时长测试与自适应采样
+	v.checkDuration("duration", yc.Duration, false, "duration")
+	if yc.Duration != "" && yc.Load.RPS > 0 {
+		v.add("duration", "开环压测使用 load.duration 控制时长", "duration")
+	}
+	if yc.Adaptive.CIWidth != "" {
+		if _, _, err := parseCIWidth(yc.Adaptive.CIWidth); err != nil {
+			v.add("adaptive.ci_width", err.Error(), "adaptive", "ci_width")
+		}
+		if yc.Load.RPS > 0 {
+			v.add("adaptive", "开环压测按速率发送，不支持自适应采样", "adaptive")
+		}
+		// 未配置 max_count 时以 test_count 为上限，配置了 duration 时不限
+		maxCount := yc.Adaptive.MaxCount
+		if maxCount == 0 && yc.Duration == "" {
+			maxCount = yc.TestCount
+		}
+		if samples := maxCount * max(yc.Concurrency, 1); maxCount > 0 && samples < minConvergeSamples {
+			v.add("adaptive.max_count", fmt.Sprintf("至少需要 %d 个成功样本才判断收敛，最多 %d 轮 × 每轮 %d 个请求不会收敛", minConvergeSamples, maxCount, max(yc.Concurrency, 1)), "adaptive")
+		}
+	} else if yc.Adaptive.MinCount != 0 || yc.Adaptive.MaxCount != 0 {
+		v.add("adaptive.ci_width", "需要配置 adaptive.ci_width 才会启用自适应采样", "adaptive")
+	}
+	switch {
+	case yc.Adaptive.MinCount < 0:
+		v.add("adaptive.min_count", fmt.Sprintf("不能小于 0，当前为 %d", yc.Adaptive.MinCount), "adaptive", "min_count")
+	case yc.Adaptive.MaxCount < 0:
+		v.add("adaptive.max_count", fmt.Sprintf("不能小于 0，当前为 %d", yc.Adaptive.MaxCount), "adaptive", "max_count")
+	case yc.Adaptive.MaxCount > 0 && yc.Adaptive.MinCount > yc.Adaptive.MaxCount:
+		v.add("adaptive.min_count", fmt.Sprintf("不能大于 max_count（%d > %d）", yc.Adaptive.MinCount, yc.Adaptive.MaxCount), "adaptive", "min_count")
+	}
+
+	// 开环压测
+	switch {
+	case yc.Load.RPS < 0:
+		v.add("load.rps", fmt.Sprintf("不能小于 0，当前为 %g", yc.Load.RPS), "load", "rps")
+	case yc.Load.RPS == 0 && yc.Load.Duration != "":
+		v.add("load.duration", "需要同时配置 load.rps 才会启用开环压测", "load", "duration")
+	case yc.Load.RPS > 0 && yc.ConnMode != "" && yc.ConnMode != "warm":
+		v.add("connection_mode", fmt.Sprintf("开环压测复用连接池，不支持 %s 连接模式", yc.ConnMode), "connection_mode")
+	}
+	v.checkDuration("load.duration", yc.Load.Duration, false, "load", "duration")
+	if yc.Load.RPS > 0 {
+		d, err := time.ParseDuration(yc.Load.Duration)
+		if err != nil {
+			d = 30 * time.Second // 未配置时的默认值
+		}
+		if d > 0 && math.Round(yc.Load.RPS*d.Seconds()) < 1 {
+			v.add("load", fmt.Sprintf("%g rps × %s 不足一个请求", yc.Load.RPS, d), "load")
+		}
+	}
+
+	// 服务端耗时来源
+	for i, t := range yc.Timing {
+		field := fmt.Sprintf("server_timing[%d]", i)
+		if t.Header == "" {
+			v.add(field+".header", "不能为空", "server_timing", i)
+		}
+		switch t.Unit {
+		case "", "s", "ms", "us":
+		default:
+			v.add(field+".unit", fmt.Sprintf("未知单位 %q，可选值: s, ms, us", t.Unit), "server_timing", i, "unit")
+		}
+		if t.Pattern != "" {
+			re, err := regexp.Compile(t.Pattern)
+			switch {
+			case err != nil:
+				v.add(field+".pattern", fmt.Sprintf("正则表达式无效: %v", err), "server_timing", i, "pattern")
+			case re.NumSubexp() == 0:
+				v.add(field+".pattern", "正则表达式需要一个捕获组来提取数值", "server_timing", i, "pattern")
+			}
+		}
+	}
+
+	// 缓存状态识别
+	for status := range yc.Cache.Keywords {
+		if _, ok := defaultCacheRules.Keywords[strings.ToUpper(status)]; !ok {
+			v.add("cache_status.keywords."+status, "未知缓存状态，可选值: HIT, MISS, STALE, BYPASS", "cache_status", "keywords", status)
+		}
+	}
+
+	// TLS 与响应体
+	if yc.TLS.EarlyData && !yc.TLS.SessionResumption {
+		v.add("tls.early_data", "0-RTT 依赖会话复用，需要同时启用 tls.session_resumption", "tls", "early_data")
+	}
+	if yc.TLS.CertExpiryDays != nil && *yc.TLS.CertExpiryDays < 0 {
+		v.add("tls.cert_expiry_warn_days", "不能小于 0", "tls", "cert_expiry_warn_days")
+	}
+	v.checkCerts("tls", yc.TLS.yamlCerts, "tls")
+	if yc.Body.MaxBytes < 0 {
+		v.add("body.max_bytes", "不能小于 0", "body", "max_bytes")
+	}
+
+	// 回归阈值
+	for i, t := range yc.Regression.Thresholds {
+		field := fmt.Sprintf("regression.thresholds[%d]", i)
+		if _, ok := lookupMetric(t.Metric); !ok {
+			v.add(field+".metric", fmt.Sprintf("未知指标 %q，可选值: %s", t.Metric, strings.Join(metricNames(), ", ")), "regression", "thresholds", i, "metric")
+		}
+		if t.MaxRegression == "" && t.Min == nil && t.Max == nil {
+			v.add(field, "至少需要设置 max_regression、min、max 之一", "regression", "thresholds", i)
+		}
+		if t.MaxRegression != "" {
+			if _, err := parsePercent(t.MaxRegression); err != nil {
+				v.add(field+".max_regression", err.Error(), "regression", "thresholds", i, "max_regression")
+			}
+		}
+		if t.Min != nil && t.Max != nil && *t.Min > *t.Max {
+			v.add(field, fmt.Sprintf("min (%g) 大于 max (%g)", *t.Min, *t.Max), "regression", "thresholds", i)
+		}
+	}
+
+	// SLO 断言
+	for i, expr := range yc.SLO {
+		if _, err := parseSLO(expr); err != nil {
+			v.add(fmt.Sprintf("slo[%d]", i), err.Error(), "slo", i)
+		}
+	}
+
+	// 守护模式
+	if yc.Monitor.Listen != "" {
+		if _, port, err := net.SplitHostPort(yc.Monitor.Listen); err != nil || port == "" {
+			v.add("monitor.listen", fmt.Sprintf("无效的监听地址 %q（示例: :9108、127.0.0.1:9108）", yc.Monitor.Listen), "monitor", "listen")
+		}
+	}
+	v.checkDuration("monitor.interval", yc.Monitor.Interval, false, "monitor", "interval")
+
+	// 测试目标
+	targetNames := make(map[string]int)
+	for i, t := range yc.Targets {
+		field := fmt.Sprintf("targets[%d]", i)
+		v.checkPath(field+".path", t.Path, "targets", i, "path")
+		name := t.Name
+		if name == "" {
+			domain := t.Domain
+			if domain == "" {
+				domain = yc.Domain
+			}
+			name = domain + t.Path
+		}
+		if first, ok := targetNames[name]; ok {
+			v.add(field+".name", fmt.Sprintf("与 targets[%d] 重名 %q", first, name), "targets", i, "name")
+		} else {
+			targetNames[name] = i
+		}
+	}
+
+	// 节点
+	if len(yc.Endpoints) == 0 {
+		v.add("endpoints", "至少需要配置一个节点", "endpoints")
+	}
+	seen := make(map[string]int)
+	for i, ep := range yc.Endpoints {
+		field := fmt.Sprintf("endpoints[%d]", i)
+		if ep.Name == "" {
+			v.add(field+".name", "不能为空", "endpoints", i, "name")
+		}
+		switch {
+		case ep.IP == "":
+			v.add(field+".ip", "不能为空", "endpoints", i, "ip")
+		case net.ParseIP(ep.IP) == nil:
+			v.add(field+".ip", fmt.Sprintf("无效的 IP 地址 %q", ep.IP), "endpoints", i, "ip")
+		}
+
+		proto, ok := lookupProtocol(ep.Protocol)
+		if !ok {
+			v.add(field+".protocol", fmt.Sprintf("未知协议 %q，可选值: HTTP/1.1, HTTP/2, HTTP/3, http, h2c", ep.Protocol), "endpoints", i, "protocol")
+		}
+		switch ep.Scheme {
+		case "":
+		case "https":
+			if ok && !proto.Secure() {
+				v.add(field+".scheme", fmt.Sprintf("明文协议 %s 不能使用 https", proto), "endpoints", i, "scheme")
+			}
+		case "http":
+			if ok && proto.Secure() {
+				v.add(field+".scheme", fmt.Sprintf("协议 %s 需要 TLS，不能使用 http（明文请使用 http 或 h2c 协议）", proto), "endpoints", i, "scheme")
+			}
+		default:
+			v.add(field+".scheme", fmt.Sprintf("未知 scheme %q，可选值: http, https", ep.Scheme), "endpoints", i, "scheme")
+		}
+		if ep.Port < 0 || ep.Port > 65535 {
+			v.add(field+".port", fmt.Sprintf("端口超出范围: %d", ep.Port), "endpoints", i, "port")
+		}
+		v.checkPath(field+".path", ep.Path, "endpoints", i, "path")
+		v.checkDuration(field+".timeout", ep.Timeout, false, "endpoints", i, "timeout")
+		for j, expr := range ep.SLO {
+			if _, err := parseSLO(expr); err != nil {
+				v.add(fmt.Sprintf("%s.slo[%d]", field, j), err.Error(), "endpoints", i, "slo", j)
+			}
+		}
+		if ep.TLS != (yamlCerts{}) && ok && !proto.Secure() {
+			v.add(field+".tls", fmt.Sprintf("明文协议 %s 不使用 TLS，证书配置不会生效", proto), "endpoints", i, "tls")
+		}
+		v.checkCerts(field+".tls", ep.TLS, "endpoints", i, "tls")
+
+		// 名称 + 协议相同的节点结果会互相覆盖
+		if ep.Name != "" && ok {
+			key := ep.Name + "|" + proto.String()
+			if first, dup := seen[key]; dup {
+				v.add(field, fmt.Sprintf("与 endpoints[%d] 的名称和协议相同 (%s, %s)，结果会互相覆盖", first, ep.Name, proto), "endpoints", i)
+			} else {
+				seen[key] = i
+			}
+		}
+	}
+
+	return v.errs
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadConfigErrors 写入临时配置文件并加载，返回配置校验问题
+func loadConfigErrors(t *testing.T, content string, overrides ConfigOverrides) ConfigErrors {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadConfig(path, overrides)
+	var errs ConfigErrors
+	if err != nil && !errors.As(err, &errs) {
+		t.Fatalf("期望配置校验错误，实际: %v", err)
+	}
+	return errs
+}
+
+func TestValidateConfigLines(t *testing.T) {
+	const content = `domain: "example.com"
+test_count: 10
+timeout: "abc"
+interval: "100ms"
+server_timing:
+  - header: "x-time"
+    unit: "ns"
+endpoints:
+  - name: "节点A"
+    ip: "1.2.3.4"
+    protocol: "http9"
+  - name: "节点B"
+    ip: "1.2.3"
+    protcol: "http2"
+targets:
+  - name: "首页"
+    path: "/"
+  - name: "首页"
+    path: "index.html"
+regression:
+  thresholds:
+    - metric: "ttfb_p95"
+      max_regression: "abc"
+    - metric: "ttfb_p100"
+      max: 300
+slo:
+  - "ttfb_p99 < 300ms"
+  - "ttfb_p99 < fast"
+`
+	want := ConfigErrors{
+		{Line: 3, Field: "timeout"},
+		{Line: 7, Field: "server_timing[0].unit"},
+		{Line: 11, Field: "endpoints[0].protocol"},
+		{Line: 13, Field: "endpoints[1].ip"},
+		{Line: 14, Field: "protcol"}, // 未知配置项
+		{Line: 18, Field: "targets[1].name"},
+		{Line: 19, Field: "targets[1].path"},
+		{Line: 23, Field: "regression.thresholds[0].max_regression"},
+		{Line: 24, Field: "regression.thresholds[1].metric"},
+		{Line: 28, Field: "slo[1]"},
+	}
+
+	errs := loadConfigErrors(t, content, ConfigOverrides{})
+	if len(errs) != len(want) {
+		t.Fatalf("共 %d 个问题, 期望 %d 个:\n%v", len(errs), len(want), errs)
+	}
+	for i, w := range want {
+		if errs[i].Line != w.Line || errs[i].Field != w.Field {
+			t.Errorf("第 %d 个问题 = 第 %d 行 %s（%s）, 期望第 %d 行 %s", i+1, errs[i].Line, errs[i].Field, errs[i].Msg, w.Line, w.Field)
+		}
+	}
+}
+
+func TestValidateConfigOverriddenLines(t *testing.T) {
+	const content = `domain: "example.com"
+test_count: 10
+interval: "100ms"
+endpoints:
+  - name: "节点A"
+    ip: "1.2.3.4"
+    protocol: "h2"
+    timeout: "-1s"
+`
+	// 被命令行覆盖的配置项不再对应文件中的行，排在最后
+	errs := loadConfigErrors(t, content, ConfigOverrides{Interval: "soon"})
+	want := ConfigErrors{
+		{Line: 8, Field: "endpoints[0].timeout"},
+		{Line: 0, Field: "interval"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("共 %d 个问题, 期望 %d 个:\n%v", len(errs), len(want), errs)
+	}
+	for i, w := range want {
+		if errs[i].Line != w.Line || errs[i].Field != w.Field {
+			t.Errorf("第 %d 个问题 = 第 %d 行 %s（%s）, 期望第 %d 行 %s", i+1, errs[i].Line, errs[i].Field, errs[i].Msg, w.Line, w.Field)
+		}
+	}
+}
+
+func TestValidateConfigValid(t *testing.T) {
+	const content = `domain: "example.com"
+test_count: 10
+endpoints:
+  - name: "节点A"
+    ip: "1.2.3.4"
+    protocol: "h2"
+`
+	if errs := loadConfigErrors(t, content, ConfigOverrides{}); len(errs) > 0 {
+		t.Errorf("有效配置不应报错: %v", errs)
+	}
+}